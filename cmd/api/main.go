@@ -0,0 +1,225 @@
+// cmd/api/main.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"payslip/config"
+	"payslip/internal/api/handlers"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/database"
+	"payslip/internal/infrastructure/notify"
+	"payslip/internal/infrastructure/repository"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
+)
+
+func main() {
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	auditKey, auditPublicKey, err := auth.LoadAuditKey(cfg.AuditSigningKeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load audit signing key: %v", err)
+	}
+	db := database.NewGORM(cfg.DatabaseURL, auditKey)
+	database.Migrate(db)
+
+	keys, err := loadKeySet(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	attendanceRepo := repository.NewAttendanceRepository(db)
+	payrollRepo := repository.NewPayrollRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	scheduleRepo := repository.NewScheduleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+
+	// Background jobs work across tenants and stop when the server is asked
+	// to shut down.
+	ctx, stop := signal.NotifyContext(tenant.System(context.Background()), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var jobs sync.WaitGroup
+
+	// Services write audit entries to the sink chosen by AUDIT_SINK. Entries
+	// written off the request path are always queued by an async sink.
+	var auditSink interfaces.AuditSink = auditRepo
+	var auditRecorder *services.AsyncAuditSink
+	switch cfg.AuditSink {
+	case "async":
+		auditRecorder = services.NewAsyncAuditSink(auditRepo, cfg.AuditBatchSize, cfg.AuditFlushInterval)
+		auditSink = auditRecorder
+	case "outbox":
+		outbox := repository.NewAuditOutboxRepository(db)
+		auditSink = outbox
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			services.RunAuditOutboxRelay(ctx, outbox, cfg.AuditBatchSize, cfg.AuditFlushInterval)
+		}()
+	}
+	if auditRecorder == nil {
+		auditRecorder = services.NewAsyncAuditSink(auditSink, cfg.AuditBatchSize, cfg.AuditFlushInterval)
+	}
+
+	policy := &services.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+	if err := policy.LoadBreachedPasswords(cfg.PasswordBreachedListFile); err != nil {
+		log.Fatalf("Failed to load breached passwords: %v", err)
+	}
+	notifier, err := notify.New(cfg.Notifier, cfg.NotifierFile)
+	if err != nil {
+		log.Fatalf("Failed to create notifier: %v", err)
+	}
+	var attemptStore interfaces.LoginAttemptStore = repository.NewLoginAttemptRepository(db)
+	if cfg.LoginAttemptStore == "memory" {
+		attemptStore = repository.NewMemoryLoginAttemptRepository()
+	}
+	guard := services.NewLoginGuard(attemptStore, cfg.LoginMaxFailures, cfg.LoginLockoutDuration, cfg.LoginBackoffBase, cfg.LoginBackoffMax)
+
+	authService := auth.NewJWTService(keys, tokenRepo, userRepo, roleRepo, auditSink, apiKeyRepo, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.MFAChallengeTTL)
+	userService := services.NewUserService(userRepo, roleRepo, authService, auditSink, policy, notifier, cfg.PasswordResetTTL, guard)
+	mfaService := services.NewMFAService(repository.NewMFARepository(db), userRepo, auditSink, guard, cfg.MFAIssuer, cfg.MFAEnforcedRoles)
+	ssoService, err := newSSOService(cfg, db, userRepo, roleRepo, auditSink)
+	if err != nil {
+		log.Fatalf("Failed to configure single sign-on: %v", err)
+	}
+	attendanceService := services.NewAttendanceService(attendanceRepo, userRepo, locationRepo, auditSink, cfg.AttendanceLocationPolicy)
+	payrollService := services.NewPayrollService(payrollRepo, attendanceRepo, orgRepo, auditSink, auditRecorder)
+	scheduleService := services.NewScheduleService(scheduleRepo, attendanceRepo, auditSink)
+
+	if cfg.PeriodGeneratorInterval > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			scheduleService.RunGenerator(ctx, cfg.PeriodGeneratorInterval)
+		}()
+	}
+
+	if cfg.AuditArchiveInterval > 0 {
+		archiveService, err := services.NewAuditArchiveService(repository.NewAuditArchiveRepository(db), auditSink, cfg.AuditArchiveDir, cfg.AuditRetention, cfg.AuditRetentionRules, cfg.AuditRestoreHold)
+		if err != nil {
+			log.Fatalf("Failed to configure audit archival: %v", err)
+		}
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			archiveService.RunArchiver(ctx, cfg.AuditArchiveInterval)
+		}()
+	}
+
+	e := echo.New()
+	e.IPExtractor, err = ipExtractor(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	e.Use(middleware.RequestID())
+	e.Use(handlers.LoggingMiddleware())
+	e.Use(handlers.AuditMiddleware(auditRecorder))
+
+	registerRoutes(e, authService, routeHandlers{
+		auth:         handlers.NewAuthHandler(userService, mfaService, ssoService, authService),
+		user:         handlers.NewUserHandler(userService),
+		role:         handlers.NewRoleHandler(services.NewRoleService(roleRepo, auditSink)),
+		organization: handlers.NewOrganizationHandler(services.NewOrganizationService(orgRepo, attendanceRepo, auditSink)),
+		apiKey:       handlers.NewAPIKeyHandler(services.NewAPIKeyService(apiKeyRepo, auditSink, cfg.APIKeyDefaultTTL)),
+		audit:        handlers.NewAuditHandler(services.NewAuditService(auditRepo, auditPublicKey)),
+		attendance:   handlers.NewAttendanceHandler(attendanceService),
+		location:     handlers.NewLocationHandler(services.NewLocationService(locationRepo, auditSink)),
+		schedule:     handlers.NewScheduleHandler(scheduleService),
+		payroll:      handlers.NewPayrollHandler(payrollService),
+	})
+
+	go func() {
+		if err := e.Start(":" + cfg.Port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server stopped: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down the server: %v", err)
+	}
+	jobs.Wait()
+	// Nothing writes audit entries any more, so the queued ones can be flushed.
+	auditRecorder.Close()
+}
+
+// loadKeySet uses the keys in JWT_KEYS_DIR when it is set, JWT_SECRET
+// otherwise.
+func loadKeySet(cfg *config.Config) (*auth.KeySet, error) {
+	if cfg.JWTKeysDir != "" {
+		return auth.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKeyID)
+	}
+	return auth.NewHMACKeySet(cfg.JWTSecret)
+}
+
+// ipExtractor takes the client IP from X-Forwarded-For when the request comes
+// through one of TRUSTED_PROXIES, and from the connection otherwise, so
+// clients cannot pick the IP that login throttling and office networks see.
+func ipExtractor(cfg *config.Config) (echo.IPExtractor, error) {
+	if cfg.TrustedProxies == "" {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(cfg.TrustedProxies, ",") {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// newSSOService returns nil when OIDC_ISSUER is unset, which leaves single
+// sign-on disabled.
+func newSSOService(cfg *config.Config, db *gorm.DB, userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, auditSink interfaces.AuditSink) (interfaces.SSOService, error) {
+	if cfg.OIDCIssuer == "" {
+		return nil, nil
+	}
+	tenantID := models.DefaultTenantID
+	if cfg.OIDCTenantID != "" {
+		id, err := uuid.Parse(cfg.OIDCTenantID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDC_TENANT_ID: %w", err)
+		}
+		tenantID = id
+	}
+	provider := auth.NewOIDCClient(cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL, cfg.OIDCScopes, cfg.OIDCGroupsClaim)
+	return services.NewSSOService(provider, repository.NewSSORepository(db), userRepo, roleRepo, auditSink, cfg.OIDCIssuer, cfg.OIDCRoleMapping, cfg.OIDCDefaultRole, cfg.OIDCJITProvisioning, tenantID, cfg.OIDCStateTTL)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"payslip/internal/api/handlers"
+	"payslip/internal/domain/models"
+	"payslip/internal/infrastructure/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+type routeHandlers struct {
+	auth         *handlers.AuthHandler
+	user         *handlers.UserHandler
+	role         *handlers.RoleHandler
+	organization *handlers.OrganizationHandler
+	apiKey       *handlers.APIKeyHandler
+	audit        *handlers.AuditHandler
+	attendance   *handlers.AttendanceHandler
+	location     *handlers.LocationHandler
+	schedule     *handlers.ScheduleHandler
+	payroll      *handlers.PayrollHandler
+}
+
+// registerRoutes mounts the endpoints listed in the README, each behind the
+// permission the README gives it.
+func registerRoutes(e *echo.Echo, authService auth.AuthService, h routeHandlers) {
+	require := func(permissions ...string) echo.MiddlewareFunc {
+		return handlers.AuthMiddleware(authService, permissions...)
+	}
+	authenticated := require()
+
+	// Authentication
+	e.POST("/login", h.auth.Login)
+	e.GET("/.well-known/jwks.json", h.auth.JWKS)
+	e.POST("/login/mfa", h.auth.LoginMFA)
+	e.POST("/login/mfa/enroll", h.auth.LoginMFAEnroll)
+	e.GET("/login/oidc", h.auth.LoginOIDC)
+	e.GET("/login/oidc/callback", h.auth.LoginOIDCCallback)
+	e.POST("/token/refresh", h.auth.Refresh)
+	e.POST("/password-reset", h.auth.ResetPassword)
+	e.POST("/logout", h.auth.Logout, authenticated)
+	e.POST("/password", h.auth.ChangePassword, authenticated)
+	e.POST("/mfa/enroll", h.auth.EnrollMFA, authenticated)
+	e.POST("/mfa/confirm", h.auth.ConfirmMFA, authenticated)
+	e.POST("/mfa/disable", h.auth.DisableMFA, authenticated)
+
+	// Users
+	e.POST("/register", h.auth.Register, require(models.PermUserRegister))
+	e.POST("/users/import", h.auth.ImportUsers, require(models.PermUserRegister))
+	e.GET("/users/:user_id", h.user.GetUser, require(models.PermUserManage))
+	e.PUT("/users/:user_id", h.user.UpdateProfile, require(models.PermUserManage))
+	e.POST("/users/:user_id/status", h.user.ChangeStatus, require(models.PermUserManage))
+	e.PUT("/users/:user_id/sso", h.auth.LinkSSO, require(models.PermUserManage))
+	e.GET("/users/:user_id/salary", h.user.ListSalaryChanges, require(models.PermSalaryManage))
+	e.POST("/users/:user_id/salary", h.user.SetSalary, require(models.PermSalaryManage))
+	e.POST("/users/:user_id/password-reset", h.auth.RequestPasswordReset, require(models.PermPasswordReset))
+	e.POST("/users/:user_id/revoke-sessions", h.auth.RevokeUserSessions, require(models.PermSessionRevoke))
+	e.POST("/tokens/revoke", h.auth.RevokeToken, require(models.PermSessionRevoke))
+
+	// Roles and organization
+	e.GET("/roles", h.role.ListRoles, require(models.PermRoleManage))
+	e.POST("/roles", h.role.CreateRole, require(models.PermRoleManage))
+	e.PUT("/roles/:role", h.role.UpdateRolePermissions, require(models.PermRoleManage))
+	e.DELETE("/roles/:role", h.role.DeleteRole, require(models.PermRoleManage))
+	e.GET("/departments", h.organization.ListDepartments, require(models.PermOrgManage))
+	e.POST("/departments", h.organization.CreateDepartment, require(models.PermOrgManage))
+	e.DELETE("/departments/:department_id", h.organization.DeleteDepartment, require(models.PermOrgManage))
+	e.GET("/cost-centers", h.organization.ListCostCenters, require(models.PermOrgManage))
+	e.POST("/cost-centers", h.organization.CreateCostCenter, require(models.PermOrgManage))
+	e.DELETE("/cost-centers/:cost_center_id", h.organization.DeleteCostCenter, require(models.PermOrgManage))
+	e.PUT("/users/:user_id/organization", h.organization.AssignUser, require(models.PermOrgManage))
+	e.GET("/team", h.organization.ListDirectReports, require(models.PermTeamRead))
+	e.GET("/team/:period_id", h.organization.GetTeamActivity, require(models.PermTeamRead))
+
+	// API keys and audit log
+	e.GET("/api-keys", h.apiKey.ListAPIKeys, require(models.PermAPIKeyManage))
+	e.POST("/api-keys", h.apiKey.CreateAPIKey, require(models.PermAPIKeyManage))
+	e.DELETE("/api-keys/:api_key_id", h.apiKey.RevokeAPIKey, require(models.PermAPIKeyManage))
+	e.GET("/audit-logs", h.audit.SearchAuditLogs, require(models.PermAuditRead))
+	e.GET("/audit-logs/export", h.audit.ExportAuditLogs, require(models.PermAuditRead))
+	e.GET("/audit-logs/verify", h.audit.VerifyAuditChain, require(models.PermAuditRead))
+	e.GET("/audit-logs/:table/:record_id", h.audit.RecordHistory, require(models.PermAuditRead))
+
+	// Attendance periods and pay schedules
+	e.POST("/attendance-period", h.attendance.CreateAttendancePeriod, require(models.PermPeriodManage))
+	e.GET("/attendance-periods", h.attendance.ListAttendancePeriods, require(models.PermPeriodManage))
+	e.GET("/attendance-periods/:period_id", h.attendance.GetAttendancePeriod, require(models.PermPeriodManage))
+	e.PUT("/attendance-periods/:period_id", h.attendance.UpdateAttendancePeriod, require(models.PermPeriodManage))
+	e.DELETE("/attendance-periods/:period_id", h.attendance.DeleteAttendancePeriod, require(models.PermPeriodManage))
+	e.GET("/pay-schedules", h.schedule.ListSchedules, require(models.PermPeriodManage))
+	e.POST("/pay-schedules", h.schedule.CreateSchedule, require(models.PermPeriodManage))
+	e.POST("/pay-schedules/generate", h.schedule.GeneratePeriods, require(models.PermPeriodManage))
+	e.DELETE("/pay-schedules/:schedule_id", h.schedule.DeactivateSchedule, require(models.PermPeriodManage))
+
+	// Attendance, overtime and reimbursements
+	e.POST("/attendance", h.attendance.SubmitAttendance, require(models.PermAttendanceSubmit))
+	e.POST("/attendance/import", h.attendance.ImportAttendance, require(models.PermAttendanceImport))
+	e.POST("/overtime", h.attendance.SubmitOvertime, require(models.PermOvertimeSubmit))
+	e.POST("/reimbursement", h.attendance.SubmitReimbursementByID, require(models.PermReimbursementSubmit))
+	e.GET("/attendance-reviews", h.attendance.ListFlaggedAttendances, require(models.PermAttendanceReview))
+	e.POST("/attendance-reviews/:attendance_id", h.attendance.ReviewAttendance, require(models.PermAttendanceReview))
+	e.GET("/office-networks", h.location.ListOfficeNetworks, require(models.PermLocationManage))
+	e.POST("/office-networks", h.location.CreateOfficeNetwork, require(models.PermLocationManage))
+	e.DELETE("/office-networks/:network_id", h.location.DeleteOfficeNetwork, require(models.PermLocationManage))
+	e.GET("/geofences", h.location.ListGeofences, require(models.PermLocationManage))
+	e.POST("/geofences", h.location.CreateGeofence, require(models.PermLocationManage))
+	e.DELETE("/geofences/:geofence_id", h.location.DeleteGeofence, require(models.PermLocationManage))
+
+	// Payroll
+	e.POST("/payroll/:period_id", h.payroll.RunPayroll, require(models.PermPayrollRun))
+	e.GET("/payroll-summary/:period_id", h.payroll.GeneratePayrollSummary, require(models.PermPayrollSummaryRead))
+	e.GET("/payslip/:period_id", h.payroll.GeneratePayslip, require(models.PermPayslipReadSelf))
+	e.GET("/payslip/:period_id/users/:user_id", h.payroll.GetEmployeePayslip, require(models.PermPayslipReadAny))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"payslip/config"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/repository"
+
+	"gorm.io/gorm"
+)
+
+func runImportAttendance(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import-attendance", flag.ExitOnError)
+	file := fs.String("file", "", "path to the CSV file (username,date[,overtime_hours])")
+	periodID := fs.String("period", "", "attendance period ID")
+	admin := fs.String("admin", "admin", "username of the admin the import is attributed to")
+	dryRun := fs.Bool("dry-run", false, "validate rows without inserting them")
+	fs.Parse(args)
+
+	if *file == "" || *periodID == "" {
+		fs.Usage()
+		return fmt.Errorf("-file and -period are required")
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	actor, err := userRepo.FindByUsername(ctx, *admin)
+	if err != nil {
+		return fmt.Errorf("admin %s: %w", *admin, err)
+	}
+	// Import into the admin's tenant, as the endpoint does.
+	ctx = tenant.WithID(ctx, actor.TenantID)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	attendanceService := services.NewAttendanceService(
+		repository.NewAttendanceRepository(db),
+		userRepo,
+		repository.NewLocationRepository(db),
+		repository.NewAuditRepository(db),
+		cfg.AttendanceLocationPolicy,
+	)
+	results, importErr := attendanceService.ImportAttendance(ctx, f, *periodID, *dryRun, actor.ID, "", "")
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return err
+	}
+	return importErr
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"payslip/config"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func runVerifyAuditLog(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("verify-audit-log", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "tenant ID to check, default every tenant")
+	keyFile := fs.String("key", cfg.AuditSigningKeyFile, "Ed25519 key, private or public, to check signatures with")
+	fs.Parse(args)
+
+	_, publicKey, err := auth.LoadAuditKey(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	var tenantIDs []uuid.UUID
+	if *tenantID != "" {
+		id, err := uuid.Parse(*tenantID)
+		if err != nil {
+			return fmt.Errorf("invalid tenant ID: %w", err)
+		}
+		tenantIDs = append(tenantIDs, id)
+	} else {
+		tenants, err := repository.NewTenantRepository(db).FindTenants(ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range tenants {
+			tenantIDs = append(tenantIDs, t.ID)
+		}
+	}
+
+	auditService := services.NewAuditService(repository.NewAuditRepository(db), publicKey)
+	broken := 0
+	for _, id := range tenantIDs {
+		report, err := auditService.VerifyAuditChain(tenant.WithID(ctx, id))
+		if err != nil {
+			return err
+		}
+		if !report.Valid {
+			broken++
+			fmt.Printf("%s  BROKEN at entry %d (%s): %s\n", id, *report.BrokenAt, report.BrokenID, report.Reason)
+			continue
+		}
+		fmt.Printf("%s  ok, %d entries, last %d %s", id, report.Checked, report.LastSequence, report.LastHash)
+		if report.Archived > 0 {
+			fmt.Printf(", %d archived", report.Archived)
+		}
+		if report.Unsigned > 0 {
+			fmt.Printf(", %d unsigned", report.Unsigned)
+		}
+		fmt.Println()
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("%d of %d audit chains are broken", broken, len(tenantIDs))
+	}
+	return nil
+}
+
+func newAuditArchiveService(db *gorm.DB, cfg *config.Config, dir string) (*services.AuditArchiveService, error) {
+	return services.NewAuditArchiveService(repository.NewAuditArchiveRepository(db), repository.NewAuditRepository(db), dir, cfg.AuditRetention, cfg.AuditRetentionRules, cfg.AuditRestoreHold)
+}
+
+func runArchiveAuditLog(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("archive-audit-log", flag.ExitOnError)
+	dir := fs.String("dir", cfg.AuditArchiveDir, "directory to write archives to")
+	fs.Parse(args)
+
+	archiveService, err := newAuditArchiveService(db, cfg, *dir)
+	if err != nil {
+		return err
+	}
+	manifests, err := archiveService.ArchiveExpiredAuditLogs(ctx, time.Now())
+	for _, manifest := range manifests {
+		fmt.Printf("%s  %d entries, %d to %d: %s\n", manifest.TenantID, manifest.Entries, manifest.FirstSequence, manifest.LastSequence, filepath.Join(*dir, manifest.TenantID.String(), "manifests", manifest.ID.String()+".json"))
+	}
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("no audit entries past their retention")
+	}
+	return nil
+}
+
+func runRestoreAuditArchive(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore-audit-archive", flag.ExitOnError)
+	manifestFile := fs.String("manifest", "", "path to the manifest of the archive")
+	dir := fs.String("dir", cfg.AuditArchiveDir, "directory the archive was written to")
+	fs.Parse(args)
+
+	if *manifestFile == "" {
+		fs.Usage()
+		return fmt.Errorf("-manifest is required")
+	}
+
+	archiveService, err := newAuditArchiveService(db, cfg, *dir)
+	if err != nil {
+		return err
+	}
+	manifest, restored, err := archiveService.RestoreAuditArchive(ctx, *manifestFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  restored %d of %d entries, %d to %d\n", manifest.TenantID, restored, manifest.Entries, manifest.FirstSequence, manifest.LastSequence)
+	return nil
+}
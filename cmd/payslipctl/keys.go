@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"payslip/config"
+	"payslip/internal/infrastructure/auth"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func runGenerateSigningKey(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("generate-signing-key", flag.ExitOnError)
+	dir := fs.String("dir", cfg.JWTKeysDir, "directory holding the JWT signing keys")
+	alg := fs.String("alg", "EdDSA", "key algorithm, EdDSA or RS256")
+	kid := fs.String("kid", time.Now().UTC().Format("20060102150405"), "key ID, used as the file name")
+	fs.Parse(args)
+
+	if *dir == "" {
+		return fmt.Errorf("-dir or JWT_KEYS_DIR is required")
+	}
+
+	var key crypto.Signer
+	var err error
+	switch *alg {
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return fmt.Errorf("unsupported algorithm %q", *alg)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := auth.EncodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(*dir, *kid+".pem")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s; set JWT_ACTIVE_KEY_ID=%s to sign with it\n", path, *kid)
+	return nil
+}
+
+// loadKeySet uses the keys in JWT_KEYS_DIR when it is set, JWT_SECRET
+// otherwise, as the server does.
+func loadKeySet(cfg *config.Config) (*auth.KeySet, error) {
+	if cfg.JWTKeysDir != "" {
+		return auth.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKeyID)
+	}
+	return auth.NewHMACKeySet(cfg.JWTSecret)
+}
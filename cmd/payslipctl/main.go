@@ -0,0 +1,76 @@
+// cmd/payslipctl/main.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"payslip/config"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/database"
+
+	"gorm.io/gorm"
+)
+
+type command struct {
+	name    string
+	usage   string
+	offline bool // does not connect to the database
+	run     func(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error
+}
+
+var commands = []command{
+	{name: "import-attendance", usage: "import attendance rows from a badge system CSV", run: runImportAttendance},
+	{name: "generate-periods", usage: "create upcoming attendance periods from the pay schedules", run: runGeneratePeriods},
+	{name: "import-users", usage: "register users from a CSV or JSON file", run: runImportUsers},
+	{name: "create-tenant", usage: "create a company and its first admin user", run: runCreateTenant},
+	{name: "list-tenants", usage: "list the companies in this deployment", run: runListTenants},
+	{name: "verify-audit-log", usage: "check that the audit log hash chain is unbroken", run: runVerifyAuditLog},
+	{name: "archive-audit-log", usage: "move audit entries past their retention into archive files", run: runArchiveAuditLog},
+	{name: "restore-audit-archive", usage: "bring an archived batch of audit entries back for an investigation", run: runRestoreAuditArchive},
+	{name: "seed-dev", usage: "create an admin and employees with random salaries (development only)", run: runSeedDev},
+	{name: "mock-idp", usage: "serve a local OpenID Connect provider for trying single sign-on", offline: true, run: runMockIDP},
+	{name: "generate-signing-key", usage: "write a new JWT signing key to the keys directory", offline: true, run: runGenerateSigningKey},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		cfg := config.Load()
+		var db *gorm.DB
+		if !cmd.offline {
+			auditKey, _, err := auth.LoadAuditKey(cfg.AuditSigningKeyFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			db = database.NewGORM(cfg.DatabaseURL, auditKey)
+			database.Migrate(db)
+		}
+		// Commands work across tenants unless they scope themselves to one.
+		if err := cmd.run(tenant.System(context.Background()), db, cfg, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: payslipctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", cmd.name, cmd.usage)
+	}
+}
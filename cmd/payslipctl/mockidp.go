@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"payslip/config"
+	"payslip/internal/infrastructure/auth/mockidp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// runMockIDP serves a local OpenID Connect provider for trying single
+// sign-on. Point OIDC_ISSUER at -issuer and OIDC_CLIENT_ID at -client-id.
+func runMockIDP(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("mock-idp", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:9000", "address to listen on")
+	issuer := fs.String("issuer", "http://localhost:9000", "issuer URL, as reached by the server and the browser")
+	clientID := fs.String("client-id", "payslip", "the only accepted client ID")
+	users := fs.String("users", "alice:employees,bob:payroll-admins", "comma-separated username:group[|group...] entries")
+	fs.Parse(args)
+
+	var idpUsers []mockidp.User
+	for _, entry := range strings.Split(*users, ",") {
+		username, groups, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if username == "" {
+			continue
+		}
+		user := mockidp.User{
+			Subject:  "mock|" + username,
+			Username: username,
+			Email:    username + "@example.com",
+			Name:     strings.ToUpper(username[:1]) + username[1:],
+		}
+		if groups != "" {
+			user.Groups = strings.Split(groups, "|")
+		}
+		idpUsers = append(idpUsers, user)
+	}
+
+	provider, err := mockidp.New(*issuer, *clientID, idpUsers)
+	if err != nil {
+		return err
+	}
+	for _, u := range idpUsers {
+		fmt.Printf("user %s  subject %s  groups %v\n", u.Username, u.Subject, u.Groups)
+	}
+	log.Printf("Mock identity provider %s listening on %s", *issuer, *addr)
+	return http.ListenAndServe(*addr, provider)
+}
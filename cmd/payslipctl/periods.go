@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"payslip/config"
+	"payslip/internal/domain/services"
+	"payslip/internal/infrastructure/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func runGeneratePeriods(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("generate-periods", flag.ExitOnError)
+	asOf := fs.String("as-of", time.Now().Format("2006-01-02"), "generate periods current at or starting within lead days of this date")
+	admin := fs.String("admin", "admin", "username of the admin the generated periods are attributed to")
+	fs.Parse(args)
+
+	date, err := time.Parse("2006-01-02", *asOf)
+	if err != nil {
+		return fmt.Errorf("invalid -as-of date: %w", err)
+	}
+
+	actor, err := repository.NewUserRepository(db).FindByUsername(ctx, *admin)
+	if err != nil {
+		return fmt.Errorf("admin %s: %w", *admin, err)
+	}
+
+	scheduleService := services.NewScheduleService(
+		repository.NewScheduleRepository(db),
+		repository.NewAttendanceRepository(db),
+		repository.NewAuditRepository(db),
+	)
+	periods, err := scheduleService.GeneratePeriods(ctx, date, actor.ID, "", "")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range periods {
+		fmt.Printf("%s  %s  %s\n", p.ID, p.StartDate.Format("2006-01-02"), p.EndDate.Format("2006-01-02"))
+	}
+	fmt.Printf("created %d periods\n", len(periods))
+	return nil
+}
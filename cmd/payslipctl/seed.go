@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"payslip/config"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/repository"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// runSeedDev fills a development database with an admin and employees with
+// random usernames and salaries. It must not be run against real data.
+func runSeedDev(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed-dev", flag.ExitOnError)
+	employees := fs.Int("employees", 100, "number of employees to create")
+	adminPassword := fs.String("admin-password", "admin123", "password of the admin user, created if missing")
+	employeePassword := fs.String("employee-password", "password123", "password of every created employee")
+	fs.Parse(args)
+
+	userRepo := repository.NewUserRepository(db)
+	adminHash, err := bcrypt.GenerateFromPassword([]byte(*adminPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	employeeHash, err := bcrypt.GenerateFromPassword([]byte(*employeePassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		admin, err := userRepo.FindByUsername(tx, "admin")
+		if err != nil {
+			admin = &models.User{
+				ID:       uuid.New(),
+				Username: "admin",
+				Password: string(adminHash),
+				Role:     "admin",
+				Status:   models.UserStatusActive,
+			}
+			if err := userRepo.Create(tx, admin); err != nil {
+				return fmt.Errorf("failed to create admin: %w", err)
+			}
+			fmt.Println("created user admin")
+		}
+		tx = tenant.WithID(tx, admin.TenantID)
+
+		for created := 0; created < *employees; {
+			username := gofakeit.Username()
+			if _, err := userRepo.FindByUsername(tx, username); err == nil {
+				continue
+			}
+			salary := float64(int(gofakeit.Float64Range(2000, 10000)*100)) / 100
+			user := &models.User{
+				ID:        uuid.New(),
+				Username:  username,
+				Password:  string(employeeHash),
+				Role:      "employee",
+				Salary:    salary,
+				FullName:  gofakeit.Name(),
+				Email:     gofakeit.Email(),
+				Position:  gofakeit.JobTitle(),
+				Status:    models.UserStatusActive,
+				CreatedBy: admin.ID,
+				UpdatedBy: admin.ID,
+			}
+			if err := userRepo.Create(tx, user); err != nil {
+				return fmt.Errorf("failed to create employee %s: %w", username, err)
+			}
+			if err := userRepo.CreateSalaryChange(tx, &models.SalaryChange{
+				ID:            uuid.New(),
+				UserID:        user.ID,
+				Amount:        salary,
+				EffectiveDate: today,
+				Reason:        "Seeded salary",
+				CreatedBy:     admin.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to record salary of %s: %w", username, err)
+			}
+			created++
+		}
+		fmt.Printf("created %d employees\n", *employees)
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"payslip/config"
+	"payslip/internal/domain/services"
+	"payslip/internal/infrastructure/repository"
+
+	"gorm.io/gorm"
+)
+
+func runCreateTenant(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("create-tenant", flag.ExitOnError)
+	name := fs.String("name", "", "company name")
+	adminUsername := fs.String("admin-username", "", "username of the tenant's first admin")
+	adminPassword := fs.String("admin-password", "", "password of the tenant's first admin")
+	fs.Parse(args)
+
+	if *name == "" || *adminUsername == "" || *adminPassword == "" {
+		fs.Usage()
+		return fmt.Errorf("-name, -admin-username and -admin-password are required")
+	}
+
+	userService, err := newUserService(db, cfg)
+	if err != nil {
+		return err
+	}
+	tenantService := services.NewTenantService(repository.NewTenantRepository(db), userService)
+	t, admin, err := tenantService.CreateTenant(ctx, *name, *adminUsername, *adminPassword)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created tenant %s (%s) with admin %s\n", t.Name, t.ID, admin.Username)
+	return nil
+}
+
+func runListTenants(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	userService, err := newUserService(db, cfg)
+	if err != nil {
+		return err
+	}
+	tenants, err := services.NewTenantService(repository.NewTenantRepository(db), userService).ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tenants {
+		fmt.Printf("%s  %s\n", t.ID, t.Name)
+	}
+	return nil
+}
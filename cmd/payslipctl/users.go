@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"payslip/config"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/notify"
+	"payslip/internal/infrastructure/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func runImportUsers(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import-users", flag.ExitOnError)
+	file := fs.String("file", "", "path to the CSV or JSON file (username,role,salary[,password])")
+	format := fs.String("format", "", "csv or json, by default taken from the file extension")
+	admin := fs.String("admin", "admin", "username of the admin the import is attributed to")
+	dryRun := fs.Bool("dry-run", false, "validate rows without creating users")
+	atomic := fs.Bool("atomic", true, "create all users or none; with -atomic=false valid rows are created and failed rows skipped")
+	fs.Parse(args)
+
+	if *file == "" {
+		fs.Usage()
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(*file), ".json") {
+			*format = "json"
+		}
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	actor, err := userRepo.FindByUsername(ctx, *admin)
+	if err != nil {
+		return fmt.Errorf("admin %s: %w", *admin, err)
+	}
+	// Import into the admin's tenant, as the endpoint does.
+	ctx = tenant.WithID(ctx, actor.TenantID)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	userService, err := newUserService(db, cfg)
+	if err != nil {
+		return err
+	}
+	results, importErr := userService.ImportUsers(ctx, f, *format, *dryRun, *atomic, actor.ID, "", "")
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return err
+	}
+	return importErr
+}
+
+// newUserService builds a UserService from the configuration, as the server
+// does.
+func newUserService(db *gorm.DB, cfg *config.Config) (*services.UserService, error) {
+	policy := &services.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+	if err := policy.LoadBreachedPasswords(cfg.PasswordBreachedListFile); err != nil {
+		return nil, err
+	}
+	notifier, err := notify.New(cfg.Notifier, cfg.NotifierFile)
+	if err != nil {
+		return nil, err
+	}
+	guard := services.NewLoginGuard(repository.NewLoginAttemptRepository(db), cfg.LoginMaxFailures, cfg.LoginLockoutDuration, cfg.LoginBackoffBase, cfg.LoginBackoffMax)
+	keys, err := loadKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	authService := auth.NewJWTService(keys, repository.NewTokenRepository(db), userRepo, roleRepo, auditRepo, repository.NewAPIKeyRepository(db), cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.MFAChallengeTTL)
+
+	return services.NewUserService(
+		userRepo,
+		roleRepo,
+		authService,
+		auditRepo,
+		policy,
+		notifier,
+		cfg.PasswordResetTTL,
+		guard,
+	), nil
+}
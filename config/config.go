@@ -1,21 +1,143 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
+// DefaultJWTSecret is the placeholder JWT_SECRET. Validate refuses to run with
+// it unless asymmetric signing keys are configured.
+const DefaultJWTSecret = "your-secret-key"
+
 type Config struct {
-	DatabaseURL string
-	JWTSecret   string
-	Port        string
+	DatabaseURL              string
+	JWTSecret                string
+	JWTKeysDir               string
+	JWTActiveKeyID           string
+	Port                     string
+	TrustedProxies           string        // comma-separated CIDRs allowed to set X-Forwarded-For
+	AttendanceLocationPolicy string        // 'off', 'flag' or 'reject'
+	PeriodGeneratorInterval  time.Duration // 0 disables the in-server generator
+	AccessTokenTTL           time.Duration
+	RefreshTokenTTL          time.Duration
+	PasswordMinLength        int
+	PasswordRequireUpper     bool
+	PasswordRequireLower     bool
+	PasswordRequireDigit     bool
+	PasswordRequireSymbol    bool
+	PasswordBreachedListFile string
+	PasswordResetTTL         time.Duration
+	Notifier                 string // 'log' or 'file'
+	NotifierFile             string
+	LoginAttemptStore        string // 'postgres' or 'memory'
+	LoginMaxFailures         int
+	LoginLockoutDuration     time.Duration
+	LoginBackoffBase         time.Duration
+	LoginBackoffMax          time.Duration
+	MFAEnforcedRoles         string // comma-separated roles that must use MFA
+	MFAIssuer                string
+	MFAChallengeTTL          time.Duration
+	OIDCIssuer               string // empty disables single sign-on
+	OIDCClientID             string
+	OIDCClientSecret         string // empty for a public client using PKCE only
+	OIDCRedirectURL          string
+	OIDCScopes               string
+	OIDCGroupsClaim          string
+	OIDCRoleMapping          string // comma-separated group=role pairs, first match wins
+	OIDCDefaultRole          string // role for users in no mapped group, empty to refuse them
+	OIDCJITProvisioning      bool
+	OIDCTenantID             string // tenant of SSO users, empty for the default tenant
+	OIDCStateTTL             time.Duration
+	APIKeyDefaultTTL         time.Duration
+	AuditSigningKeyFile      string // Ed25519 PEM key, empty leaves audit entries unsigned
+	AuditSink                string // 'sync', 'async' or 'outbox'
+	AuditBatchSize           int
+	AuditFlushInterval       time.Duration
+	AuditRetention           time.Duration // 0 keeps audit entries forever
+	AuditRetentionRules      string        // comma-separated table[:action]=duration pairs
+	AuditArchiveDir          string
+	AuditArchiveInterval     time.Duration // 0 disables the in-server archiver
+	AuditRestoreHold         time.Duration
 }
 
 func Load() *Config {
 	return &Config{
-		DatabaseURL: getEnv("DATABASE_URL", "host=localhost user=postgres password=1234 dbname=payslip port=5432 sslmode=disable"),
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
-		Port:        getEnv("PORT", "8084"),
+		DatabaseURL:              getEnv("DATABASE_URL", "host=localhost user=postgres password=1234 dbname=payslip port=5432 sslmode=disable"),
+		JWTSecret:                getEnv("JWT_SECRET", DefaultJWTSecret),
+		JWTKeysDir:               getEnv("JWT_KEYS_DIR", ""),
+		JWTActiveKeyID:           getEnv("JWT_ACTIVE_KEY_ID", ""),
+		Port:                     getEnv("PORT", "8084"),
+		TrustedProxies:           getEnv("TRUSTED_PROXIES", ""),
+		AttendanceLocationPolicy: getEnv("ATTENDANCE_LOCATION_POLICY", "flag"),
+		PeriodGeneratorInterval:  getEnvDuration("PERIOD_GENERATOR_INTERVAL", time.Hour),
+		AccessTokenTTL:           getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:          getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		PasswordMinLength:        getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:     getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:     getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:     getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol:    getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordBreachedListFile: getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+		PasswordResetTTL:         getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		Notifier:                 getEnv("NOTIFIER", "log"),
+		NotifierFile:             getEnv("NOTIFIER_FILE", "notifications.log"),
+		LoginAttemptStore:        getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+		LoginMaxFailures:         getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginLockoutDuration:     getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginBackoffBase:         getEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		LoginBackoffMax:          getEnvDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+		MFAEnforcedRoles:         getEnv("MFA_ENFORCED_ROLES", ""),
+		MFAIssuer:                getEnv("MFA_ISSUER", "Payslip"),
+		MFAChallengeTTL:          getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		OIDCIssuer:               getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:             getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:         getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:          getEnv("OIDC_REDIRECT_URL", "http://localhost:8084/login/oidc/callback"),
+		OIDCScopes:               getEnv("OIDC_SCOPES", "openid profile email groups"),
+		OIDCGroupsClaim:          getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCRoleMapping:          getEnv("OIDC_ROLE_MAPPING", ""),
+		OIDCDefaultRole:          getEnv("OIDC_DEFAULT_ROLE", "employee"),
+		OIDCJITProvisioning:      getEnvBool("OIDC_JIT_PROVISIONING", true),
+		OIDCTenantID:             getEnv("OIDC_TENANT_ID", ""),
+		OIDCStateTTL:             getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+		APIKeyDefaultTTL:         getEnvDuration("API_KEY_DEFAULT_TTL", 90*24*time.Hour),
+		AuditSigningKeyFile:      getEnv("AUDIT_SIGNING_KEY_FILE", ""),
+		AuditSink:                getEnv("AUDIT_SINK", "sync"),
+		AuditBatchSize:           getEnvInt("AUDIT_BATCH_SIZE", 100),
+		AuditFlushInterval:       getEnvDuration("AUDIT_FLUSH_INTERVAL", time.Second),
+		AuditRetention:           getEnvDuration("AUDIT_RETENTION", 0),
+		AuditRetentionRules:      getEnv("AUDIT_RETENTION_RULES", ""),
+		AuditArchiveDir:          getEnv("AUDIT_ARCHIVE_DIR", "audit-archive"),
+		AuditArchiveInterval:     getEnvDuration("AUDIT_ARCHIVE_INTERVAL", 24*time.Hour),
+		AuditRestoreHold:         getEnvDuration("AUDIT_RESTORE_HOLD", 30*24*time.Hour),
+	}
+}
+
+// Validate reports configuration the server must not start with.
+func (c *Config) Validate() error {
+	if c.JWTKeysDir == "" && (c.JWTSecret == "" || c.JWTSecret == DefaultJWTSecret) {
+		return fmt.Errorf("JWT_SECRET is unset or the default value; set JWT_KEYS_DIR or a strong JWT_SECRET")
+	}
+	if c.OIDCIssuer != "" && c.OIDCClientID == "" {
+		return fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
 	}
+	switch c.AuditSink {
+	case "sync", "async", "outbox":
+	default:
+		return fmt.Errorf("AUDIT_SINK must be sync, async or outbox, not %q", c.AuditSink)
+	}
+	if c.AuditFlushInterval <= 0 {
+		return fmt.Errorf("AUDIT_FLUSH_INTERVAL must be positive")
+	}
+	switch c.AttendanceLocationPolicy {
+	case "off", "flag", "reject":
+	default:
+		return fmt.Errorf("ATTENDANCE_LOCATION_POLICY must be off, flag or reject, not %q", c.AttendanceLocationPolicy)
+	}
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -24,3 +146,43 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration parses a duration such as "1h" or "30m".
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	apiKeyService interfaces.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService interfaces.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey issues a key. The raw key is only ever shown in this response.
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var input struct {
+		Name      string   `json:"name"`
+		Scopes    []string `json:"scopes"`
+		ExpiresAt string   `json:"expires_at"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	claims, err := GetClaimsFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	key, raw, err := h.apiKeyService.CreateAPIKey(c.Request().Context(), input.Name, input.Scopes, input.ExpiresAt, claims.Permissions, claims.UserID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	response := apiKeyResponse(key)
+	response["message"] = "API key created, store it now as it will not be shown again"
+	response["key"] = raw
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	response := make([]map[string]interface{}, len(keys))
+	for i, key := range keys {
+		response[i] = apiKeyResponse(key)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"api_keys": response})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request().Context(), c.Param("api_key_id"), adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "API key revoked"})
+}
+
+// apiKeyResponse describes a key without its hash.
+func apiKeyResponse(key *models.APIKey) map[string]interface{} {
+	return map[string]interface{}{
+		"api_key_id":   key.ID,
+		"name":         key.Name,
+		"prefix":       key.Prefix,
+		"scopes":       strings.Split(key.Scopes, ","),
+		"expires_at":   key.ExpiresAt,
+		"last_used_at": key.LastUsedAt,
+		"last_used_ip": key.LastUsedIP,
+		"revoked_at":   key.RevokedAt,
+		"created_at":   key.CreatedAt,
+		"created_by":   key.CreatedBy,
+	}
+}
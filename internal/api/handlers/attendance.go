@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"payslip/internal/domain/interfaces"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -40,10 +41,75 @@ func (h *AttendanceHandler) CreateAttendancePeriod(c echo.Context) error {
 	})
 }
 
+func (h *AttendanceHandler) ListAttendancePeriods(c echo.Context) error {
+	page, pageSize := paginationParams(c)
+
+	periods, total, err := h.attendanceService.ListPeriods(c.Request().Context(), c.QueryParam("from"), c.QueryParam("to"), page, pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"periods":   periods,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+func (h *AttendanceHandler) GetAttendancePeriod(c echo.Context) error {
+	period, err := h.attendanceService.GetPeriod(c.Request().Context(), c.Param("period_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, period)
+}
+
+func (h *AttendanceHandler) UpdateAttendancePeriod(c echo.Context) error {
+	var input struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	period, err := h.attendanceService.UpdatePeriod(c.Request().Context(), c.Param("period_id"), input.StartDate, input.EndDate, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":   "Period updated",
+		"period_id": period.ID,
+	})
+}
+
+func (h *AttendanceHandler) DeleteAttendancePeriod(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.attendanceService.DeletePeriod(c.Request().Context(), c.Param("period_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Period deleted"})
+}
+
 func (h *AttendanceHandler) SubmitAttendance(c echo.Context) error {
 	var input struct {
-		Date     string `json:"date"`
-		PeriodID string `json:"period_id"`
+		Date      string   `json:"date"`
+		PeriodID  string   `json:"period_id"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
 	}
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
@@ -54,7 +120,7 @@ func (h *AttendanceHandler) SubmitAttendance(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	attendance, err := h.attendanceService.SubmitAttendance(c.Request().Context(), input.Date, input.PeriodID, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	attendance, err := h.attendanceService.SubmitAttendance(c.Request().Context(), input.Date, input.PeriodID, input.Latitude, input.Longitude, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -62,6 +128,42 @@ func (h *AttendanceHandler) SubmitAttendance(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":       "Attendance submitted",
 		"attendance_id": attendance.ID,
+		"period_id":     attendance.PeriodID,
+		"review_status": attendance.ReviewStatus,
+	})
+}
+
+func (h *AttendanceHandler) ListFlaggedAttendances(c echo.Context) error {
+	attendances, err := h.attendanceService.ListFlaggedAttendances(c.Request().Context(), c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"attendances": attendances})
+}
+
+func (h *AttendanceHandler) ReviewAttendance(c echo.Context) error {
+	var input struct {
+		Decision string `json:"decision"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	attendance, err := h.attendanceService.ReviewAttendance(c.Request().Context(), c.Param("attendance_id"), input.Decision, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":       "Attendance reviewed",
+		"attendance_id": attendance.ID,
+		"review_status": attendance.ReviewStatus,
 	})
 }
 
@@ -88,6 +190,7 @@ func (h *AttendanceHandler) SubmitOvertime(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":     "Overtime submitted",
 		"overtime_id": overtime.ID,
+		"period_id":   overtime.PeriodID,
 	})
 }
 
@@ -114,5 +217,39 @@ func (h *AttendanceHandler) SubmitReimbursementByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":          "Reimbursement submitted",
 		"reimbursement_id": reimbursement.ID,
+		"period_id":        reimbursement.PeriodID,
+	})
+}
+
+func (h *AttendanceHandler) ImportAttendance(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	body := c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+		}
+		defer f.Close()
+		body = f
+	}
+
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+	results, err := h.attendanceService.ImportAttendance(c.Request().Context(), body, c.QueryParam("period_id"), dryRun, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "rows": results})
+	}
+
+	message := "Attendance imported"
+	if dryRun {
+		message = "Attendance import validated"
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": message,
+		"dry_run": dryRun,
+		"rows":    results,
 	})
 }
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	auditService interfaces.AuditService
+}
+
+func NewAuditHandler(auditService interfaces.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+func (h *AuditHandler) SearchAuditLogs(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	entries, next, err := h.auditService.SearchAuditLogs(c.Request().Context(), auditQueryParams(c), c.QueryParam("cursor"), limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"audit_logs":  entries,
+		"next_cursor": next,
+	})
+}
+
+func (h *AuditHandler) RecordHistory(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	entries, next, err := h.auditService.RecordHistory(c.Request().Context(), c.Param("table"), c.Param("record_id"), c.QueryParam("cursor"), limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"table":       c.Param("table"),
+		"record_id":   c.Param("record_id"),
+		"audit_logs":  entries,
+		"next_cursor": next,
+	})
+}
+
+// ExportAuditLogs streams every matching entry as CSV or NDJSON.
+func (h *AuditHandler) ExportAuditLogs(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "csv"
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == "ndjson" {
+		contentType = "application/x-ndjson"
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=audit-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format))
+
+	err = h.auditService.ExportAuditLogs(c.Request().Context(), auditQueryParams(c), format, c.Response(), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		// The status was already sent, all we can do is cut the body short.
+		log.Printf("RequestID: %s, audit export failed: %v", c.Response().Header().Get(echo.HeaderXRequestID), err)
+		return err
+	}
+	if !c.Response().Committed {
+		c.Response().WriteHeader(http.StatusOK)
+	}
+	return nil
+}
+
+// VerifyAuditChain checks the caller's tenant's audit chain. A broken chain
+// is reported with 409 Conflict.
+func (h *AuditHandler) VerifyAuditChain(c echo.Context) error {
+	report, err := h.auditService.VerifyAuditChain(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !report.Valid {
+		return c.JSON(http.StatusConflict, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func auditQueryParams(c echo.Context) interfaces.AuditQuery {
+	return interfaces.AuditQuery{
+		UserID:    c.QueryParam("user_id"),
+		APIKeyID:  c.QueryParam("api_key_id"),
+		TableName: c.QueryParam("table"),
+		RecordID:  c.QueryParam("record_id"),
+		Action:    c.QueryParam("action"),
+		Outcome:   c.QueryParam("outcome"),
+		RequestID: c.QueryParam("request_id"),
+		Field:     c.QueryParam("field"),
+		From:      c.QueryParam("from"),
+		To:        c.QueryParam("to"),
+	}
+}
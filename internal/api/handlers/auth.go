@@ -2,27 +2,34 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"payslip/internal/domain/interfaces"
 	"payslip/internal/infrastructure/auth"
+	"strconv"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 type AuthHandler struct {
 	userService interfaces.UserService
+	mfaService  interfaces.MFAService
+	ssoService  interfaces.SSOService // nil when single sign-on is not configured
 	authService auth.AuthService
 }
 
-func NewAuthHandler(userService interfaces.UserService, authService auth.AuthService) *AuthHandler {
-	return &AuthHandler{userService: userService, authService: authService}
+func NewAuthHandler(userService interfaces.UserService, mfaService interfaces.MFAService, ssoService interfaces.SSOService, authService auth.AuthService) *AuthHandler {
+	return &AuthHandler{userService: userService, mfaService: mfaService, ssoService: ssoService, authService: authService}
 }
 
 func (h *AuthHandler) Register(c echo.Context) error {
 	var input struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-		Role     string `json:"role"`
+		Username string  `json:"username"`
+		Password string  `json:"password"`
+		Role     string  `json:"role"`
+		Salary   float64 `json:"salary"`
 	}
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
@@ -33,7 +40,10 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	user, err := h.userService.Register(c.Request().Context(), input.Username, input.Password, input.Role, userID.String(), c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	user, err := h.userService.Register(c.Request().Context(), input.Username, input.Password, input.Role, input.Salary, userID.String(), c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if errors.Is(err, interfaces.ErrUsernameTaken) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 	}
@@ -43,6 +53,64 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		"user_id":  user.ID,
 		"username": user.Username,
 		"role":     user.Role,
+		"salary":   user.Salary,
+	})
+}
+
+// ImportUsers registers users from CSV or JSON. The format comes from the
+// format query parameter, else from the uploaded file name or Content-Type.
+func (h *AuthHandler) ImportUsers(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "csv"
+		if strings.Contains(c.Request().Header.Get(echo.HeaderContentType), "json") {
+			format = "json"
+		}
+	}
+	body := c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+		}
+		defer f.Close()
+		body = f
+		if c.QueryParam("format") == "" && strings.HasSuffix(strings.ToLower(file.Filename), ".json") {
+			format = "json"
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+	atomic := true
+	if value := c.QueryParam("atomic"); value != "" {
+		atomic, _ = strconv.ParseBool(value)
+	}
+	results, err := h.userService.ImportUsers(c.Request().Context(), body, format, dryRun, atomic, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "rows": results})
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == "error" {
+			failed++
+		}
+	}
+	message := "Users imported"
+	if dryRun {
+		message = "User import validated"
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": message,
+		"dry_run": dryRun,
+		"atomic":  atomic,
+		"failed":  failed,
+		"rows":    results,
 	})
 }
 
@@ -55,19 +123,187 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
 	}
 
-	user, _, err := h.userService.Login(c.Request().Context(), input.Username, input.Password)
+	user, _, err := h.userService.Login(c.Request().Context(), input.Username, input.Password, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	var throttled *interfaces.LoginThrottledError
+	if errors.As(err, &throttled) {
+		// Login already wrote login_blocked.
+		markAudited(c)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	token, err := h.authService.GenerateToken(user.ID, user.Role)
+	required, enrolled, err := h.mfaService.Required(c.Request().Context(), user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if required {
+		challenge, err := h.authService.IssueMFAChallenge(user.ID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to generate token"})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"mfa_required": true,
+			"mfa_enrolled": enrolled,
+			"mfa_token":    challenge,
+			"user_id":      user.ID,
+		})
+	}
+
+	tokens, err := h.authService.IssueTokens(c.Request().Context(), user, c.RealIP())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to generate token"})
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"token":   token,
-		"user_id": user.ID,
-		"role":    user.Role,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user_id":       user.ID,
+		"role":          user.Role,
 	})
 }
+
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&input); err != nil || input.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	tokens, err := h.authService.RefreshTokens(c.Request().Context(), input.RefreshToken, c.RealIP())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	claims, err := GetClaimsFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.authService.Logout(c.Request().Context(), claims, input.RefreshToken, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Logged out"})
+}
+
+func (h *AuthHandler) RevokeToken(c echo.Context) error {
+	var input struct {
+		JTI    string `json:"jti"`
+		UserID string `json:"user_id"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	jti, err := uuid.Parse(input.JTI)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid jti"})
+	}
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.authService.RevokeToken(c.Request().Context(), jti, userID, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Token revoked"})
+}
+
+func (h *AuthHandler) RevokeUserSessions(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.authService.RevokeAllSessions(c.Request().Context(), userID, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "All sessions revoked"})
+}
+
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	var input struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.userService.ChangePassword(c.Request().Context(), input.OldPassword, input.NewPassword, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password changed, please log in again"})
+}
+
+func (h *AuthHandler) RequestPasswordReset(c echo.Context) error {
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	expiresAt, err := h.userService.RequestPasswordReset(c.Request().Context(), c.Param("user_id"), adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":    "Password reset token sent",
+		"expires_at": expiresAt,
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var input struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := c.Bind(&input); err != nil || input.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	if err := h.userService.ResetPassword(c.Request().Context(), input.Token, input.NewPassword, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password reset, please log in"})
+}
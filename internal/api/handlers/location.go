@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"payslip/internal/domain/interfaces"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LocationHandler struct {
+	locationService interfaces.LocationService
+}
+
+func NewLocationHandler(locationService interfaces.LocationService) *LocationHandler {
+	return &LocationHandler{locationService: locationService}
+}
+
+func (h *LocationHandler) CreateOfficeNetwork(c echo.Context) error {
+	var input struct {
+		Name string `json:"name"`
+		CIDR string `json:"cidr"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	network, err := h.locationService.CreateOfficeNetwork(c.Request().Context(), input.Name, input.CIDR, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":    "Office network created",
+		"network_id": network.ID,
+		"cidr":       network.CIDR,
+	})
+}
+
+func (h *LocationHandler) ListOfficeNetworks(c echo.Context) error {
+	networks, err := h.locationService.ListOfficeNetworks(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"networks": networks})
+}
+
+func (h *LocationHandler) DeleteOfficeNetwork(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.locationService.DeleteOfficeNetwork(c.Request().Context(), c.Param("network_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Office network deleted"})
+}
+
+func (h *LocationHandler) CreateGeofence(c echo.Context) error {
+	var input struct {
+		Name         string  `json:"name"`
+		Latitude     float64 `json:"latitude"`
+		Longitude    float64 `json:"longitude"`
+		RadiusMeters float64 `json:"radius_meters"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	geofence, err := h.locationService.CreateGeofence(c.Request().Context(), input.Name, input.Latitude, input.Longitude, input.RadiusMeters, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":     "Geofence created",
+		"geofence_id": geofence.ID,
+	})
+}
+
+func (h *LocationHandler) ListGeofences(c echo.Context) error {
+	geofences, err := h.locationService.ListGeofences(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"geofences": geofences})
+}
+
+func (h *LocationHandler) DeleteGeofence(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.locationService.DeleteGeofence(c.Request().Context(), c.Param("geofence_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Geofence deleted"})
+}
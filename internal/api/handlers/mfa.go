@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// LoginMFA completes a login that returned mfa_required.
+func (h *AuthHandler) LoginMFA(c echo.Context) error {
+	var input struct {
+		MFAToken     string `json:"mfa_token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.Bind(&input); err != nil || input.MFAToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	userID, err := h.authService.ValidateMFAChallenge(input.MFAToken)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	user, recoveryCodes, err := h.mfaService.VerifyLogin(c.Request().Context(), userID, input.Code, input.RecoveryCode, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	var throttled *interfaces.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	tokens, err := h.authService.IssueTokens(c.Request().Context(), user, c.RealIP())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to generate token"})
+	}
+
+	response := map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user_id":       user.ID,
+		"role":          user.Role,
+	}
+	if recoveryCodes != nil {
+		response["recovery_codes"] = recoveryCodes
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// LoginMFAEnroll starts enrollment during a login for users whose role
+// enforces MFA but who have no authenticator yet.
+func (h *AuthHandler) LoginMFAEnroll(c echo.Context) error {
+	var input struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	if err := c.Bind(&input); err != nil || input.MFAToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	userID, err := h.authService.ValidateMFAChallenge(input.MFAToken)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	secret, uri, err := h.mfaService.Enroll(c.Request().Context(), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"secret":           secret,
+		"provisioning_uri": uri,
+	})
+}
+
+func (h *AuthHandler) EnrollMFA(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	secret, uri, err := h.mfaService.Enroll(c.Request().Context(), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"secret":           secret,
+		"provisioning_uri": uri,
+	})
+}
+
+func (h *AuthHandler) ConfirmMFA(c echo.Context) error {
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	recoveryCodes, err := h.mfaService.Confirm(c.Request().Context(), userID, input.Code, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":        "MFA enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (h *AuthHandler) DisableMFA(c echo.Context) error {
+	var input struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.mfaService.Disable(c.Request().Context(), userID, input.Password, input.Code, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "MFA disabled"})
+}
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"payslip/internal/domain/actor"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
 	"payslip/internal/infrastructure/auth"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +25,7 @@ type contextKey string
 const (
 	userIDKey contextKey = "user_id"
 	roleKey   contextKey = "role"
+	claimsKey contextKey = "claims"
 )
 
 func LoggingMiddleware() echo.MiddlewareFunc {
@@ -31,26 +40,80 @@ func LoggingMiddleware() echo.MiddlewareFunc {
 	}
 }
 
-func AuthMiddleware(authService auth.AuthService, requiredRole string) echo.MiddlewareFunc {
+// AuthMiddleware requires a valid token holding at least one of the given
+// permissions. With no permissions, any authenticated user is accepted.
+// An API key, sent in the X-API-Key header or as a bearer token, is accepted
+// in place of a token, but only on routes that require a permission.
+func AuthMiddleware(authService auth.AuthService, permissions ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			userID, role, err := authService.ValidateToken(c.Request().Header.Get("Authorization"))
+			var claims *auth.Claims
+			var err error
+			if key := apiKeyFromRequest(c); key != "" {
+				if len(permissions) == 0 {
+					return c.JSON(http.StatusForbidden, map[string]string{"error": "API keys cannot be used here"})
+				}
+				claims, err = authService.ValidateAPIKey(c.Request().Context(), key, c.RealIP())
+			} else {
+				claims, err = authService.ValidateToken(c.Request().Context(), c.Request().Header.Get("Authorization"))
+			}
 			if err != nil {
 				return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 			}
-			if role != requiredRole {
-				return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+			// Set before checking permissions so denials are attributed.
+			ctx := tenant.WithID(c.Request().Context(), claims.TenantID)
+			if claims.APIKeyID != nil {
+				ctx = actor.WithAPIKey(ctx, *claims.APIKeyID)
 			}
-
-			ctx := context.WithValue(c.Request().Context(), userIDKey, userID)
-			ctx = context.WithValue(ctx, roleKey, role)
+			ctx = context.WithValue(ctx, userIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, roleKey, claims.Role)
+			ctx = context.WithValue(ctx, claimsKey, claims)
 			c.SetRequest(c.Request().WithContext(ctx))
 
+			if len(permissions) > 0 && !hasAnyPermission(claims.Permissions, permissions) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+			}
+
 			return next(c)
 		}
 	}
 }
 
+func apiKeyFromRequest(c echo.Context) string {
+	if key := c.Request().Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if bearer := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer "); strings.HasPrefix(bearer, models.APIKeyPrefix) {
+		return bearer
+	}
+	return ""
+}
+
+func hasAnyPermission(granted, required []string) bool {
+	for _, r := range required {
+		for _, g := range granted {
+			if g == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paginationParams reads the page and page_size query parameters, defaulting
+// to the first page of 20 items and capping page_size at 100.
+func paginationParams(c echo.Context) (int, int) {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.QueryParam("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
 func GetUserIDFromContext(ctx context.Context) (uuid.UUID, error) {
 	userIDVal := ctx.Value(userIDKey)
 	if userIDVal == nil {
@@ -62,3 +125,112 @@ func GetUserIDFromContext(ctx context.Context) (uuid.UUID, error) {
 	}
 	return userID, nil
 }
+
+func GetClaimsFromContext(ctx context.Context) (*auth.Claims, error) {
+	claims, ok := ctx.Value(claimsKey).(*auth.Claims)
+	if !ok {
+		return nil, fmt.Errorf("token claims not found in context")
+	}
+	return claims, nil
+}
+
+// AuditMiddleware records denied and failed requests in the audit log,
+// without delaying the response. It should wrap AuthMiddleware so the user and
+// tenant of authenticated requests are known.
+//   - 403 responses, and 401 responses to requests that carried credentials,
+//     are recorded as access_denied with outcome denied.
+//   - Other 4xx responses to requests that change data, and every 5xx
+//     response, are recorded as request_failed with outcome failure.
+//
+// The error message of the response is kept in the details. Responses whose
+// handler called markAudited are not recorded again.
+func AuditMiddleware(recorder interfaces.AuditRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			capture := &errorCapture{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = capture
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			if audited, _ := c.Get(auditedKey).(bool); audited {
+				return nil
+			}
+
+			status := c.Response().Status
+			method := c.Request().Method
+			credentials := c.Request().Header.Get("Authorization") != "" || c.Request().Header.Get("X-API-Key") != ""
+			var action, outcome string
+			switch {
+			case status == http.StatusForbidden || (status == http.StatusUnauthorized && credentials):
+				action, outcome = "access_denied", models.AuditOutcomeDenied
+			case status >= 500 || (status >= 400 && status != http.StatusUnauthorized && method != http.MethodGet && method != http.MethodHead):
+				action, outcome = "request_failed", models.AuditOutcomeFailure
+			default:
+				return nil
+			}
+
+			details := fmt.Sprintf("%s %s returned %d", method, c.Request().URL.Path, status)
+			var body struct {
+				Error   string `json:"error"`
+				Message string `json:"message"` // echo.HTTPError
+			}
+			if json.Unmarshal(capture.body.Bytes(), &body) == nil {
+				if body.Error == "" {
+					body.Error = body.Message
+				}
+				if body.Error != "" {
+					details += ": " + body.Error
+				}
+			}
+			userID, _ := GetUserIDFromContext(c.Request().Context())
+			recorder.Record(c.Request().Context(), &models.AuditLog{
+				ID:        uuid.New(),
+				Action:    action,
+				Outcome:   outcome,
+				TableName: "request",
+				UserID:    userID,
+				IPAddress: c.RealIP(),
+				RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+				Details:   details,
+				CreatedAt: time.Now(),
+			})
+			return nil
+		}
+	}
+}
+
+// auditedKey marks a request whose outcome the service already wrote to the
+// audit log.
+const auditedKey = "audited"
+
+// markAudited keeps AuditMiddleware from recording the response, because the
+// service already wrote a more specific entry for it.
+func markAudited(c echo.Context) {
+	c.Set(auditedKey, true)
+}
+
+// errorCapture keeps the start of error response bodies.
+type errorCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+const errorCaptureLimit = 1024
+
+func (w *errorCapture) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorCapture) Write(b []byte) (int, error) {
+	if w.status >= 400 && w.body.Len() < errorCaptureLimit {
+		w.body.Write(b[:min(len(b), errorCaptureLimit-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorCapture) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"payslip/internal/domain/interfaces"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OrganizationHandler struct {
+	orgService interfaces.OrganizationService
+}
+
+func NewOrganizationHandler(orgService interfaces.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{orgService: orgService}
+}
+
+func (h *OrganizationHandler) CreateDepartment(c echo.Context) error {
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	department, err := h.orgService.CreateDepartment(c.Request().Context(), input.Name, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":       "Department created",
+		"department_id": department.ID,
+		"name":          department.Name,
+	})
+}
+
+func (h *OrganizationHandler) ListDepartments(c echo.Context) error {
+	departments, err := h.orgService.ListDepartments(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"departments": departments})
+}
+
+func (h *OrganizationHandler) DeleteDepartment(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.orgService.DeleteDepartment(c.Request().Context(), c.Param("department_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Department deleted"})
+}
+
+func (h *OrganizationHandler) CreateCostCenter(c echo.Context) error {
+	var input struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	costCenter, err := h.orgService.CreateCostCenter(c.Request().Context(), input.Code, input.Name, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":        "Cost center created",
+		"cost_center_id": costCenter.ID,
+		"code":           costCenter.Code,
+		"name":           costCenter.Name,
+	})
+}
+
+func (h *OrganizationHandler) ListCostCenters(c echo.Context) error {
+	costCenters, err := h.orgService.ListCostCenters(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"cost_centers": costCenters})
+}
+
+func (h *OrganizationHandler) DeleteCostCenter(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.orgService.DeleteCostCenter(c.Request().Context(), c.Param("cost_center_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Cost center deleted"})
+}
+
+func (h *OrganizationHandler) AssignUser(c echo.Context) error {
+	var input struct {
+		DepartmentID string `json:"department_id"`
+		CostCenterID string `json:"cost_center_id"`
+		ManagerID    string `json:"manager_id"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	user, err := h.orgService.AssignUser(c.Request().Context(), c.Param("user_id"), input.DepartmentID, input.CostCenterID, input.ManagerID, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *OrganizationHandler) ListDirectReports(c echo.Context) error {
+	managerID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	reports, err := h.orgService.ListDirectReports(c.Request().Context(), managerID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	response := make([]map[string]interface{}, len(reports))
+	for i, report := range reports {
+		response[i] = userResponse(report)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"reports": response})
+}
+
+func (h *OrganizationHandler) GetTeamActivity(c echo.Context) error {
+	managerID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	team, err := h.orgService.GetTeamActivity(c.Request().Context(), c.Param("period_id"), c.QueryParam("user_id"), managerID)
+	if errors.Is(err, interfaces.ErrNotDirectReport) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"team": team})
+}
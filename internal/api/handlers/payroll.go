@@ -38,7 +38,21 @@ func (h *PayrollHandler) GeneratePayslip(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
-	payslip, err := h.payrollService.GeneratePayslip(c.Request().Context(), periodID, userID)
+	payslip, err := h.payrollService.GeneratePayslip(c.Request().Context(), periodID, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, payslip)
+}
+
+func (h *PayrollHandler) GetEmployeePayslip(c echo.Context) error {
+	viewerID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	payslip, err := h.payrollService.GetEmployeePayslip(c.Request().Context(), c.Param("period_id"), c.Param("user_id"), viewerID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -49,7 +63,12 @@ func (h *PayrollHandler) GeneratePayslip(c echo.Context) error {
 func (h *PayrollHandler) GeneratePayrollSummary(c echo.Context) error {
 	periodID := c.Param("period_id")
 
-	summary, err := h.payrollService.GeneratePayrollSummary(c.Request().Context(), periodID)
+	viewerID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	summary, err := h.payrollService.GeneratePayrollSummary(c.Request().Context(), periodID, viewerID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
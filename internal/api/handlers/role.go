@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RoleHandler struct {
+	roleService interfaces.RoleService
+}
+
+func NewRoleHandler(roleService interfaces.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+func (h *RoleHandler) ListRoles(c echo.Context) error {
+	roles, err := h.roleService.ListRoles(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"roles":       roles,
+		"permissions": models.Permissions,
+	})
+}
+
+func (h *RoleHandler) CreateRole(c echo.Context) error {
+	var input struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	role, err := h.roleService.CreateRole(c.Request().Context(), input.Name, input.Description, input.Permissions, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoleHandler) UpdateRolePermissions(c echo.Context) error {
+	var input struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	role, err := h.roleService.UpdateRolePermissions(c.Request().Context(), c.Param("role"), input.Permissions, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) DeleteRole(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.roleService.DeleteRole(c.Request().Context(), c.Param("role"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Role deleted"})
+}
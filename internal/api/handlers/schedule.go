@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ScheduleHandler struct {
+	scheduleService interfaces.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService interfaces.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{scheduleService: scheduleService}
+}
+
+func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
+	var input struct {
+		Name       string `json:"name"`
+		Frequency  string `json:"frequency"`
+		DayOfMonth int    `json:"day_of_month"`
+		AnchorDate string `json:"anchor_date"`
+		LeadDays   *int   `json:"lead_days"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(c.Request().Context(), input.Name, input.Frequency, input.DayOfMonth, input.AnchorDate, input.LeadDays, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":     "Pay schedule created",
+		"schedule_id": schedule.ID,
+	})
+}
+
+func (h *ScheduleHandler) ListSchedules(c echo.Context) error {
+	schedules, err := h.scheduleService.ListSchedules(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"schedules": schedules})
+}
+
+func (h *ScheduleHandler) DeactivateSchedule(c echo.Context) error {
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.scheduleService.DeactivateSchedule(c.Request().Context(), c.Param("schedule_id"), userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Pay schedule deactivated"})
+}
+
+func (h *ScheduleHandler) GeneratePeriods(c echo.Context) error {
+	asOf := time.Now()
+	if value := c.QueryParam("as_of"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid as_of date format"})
+		}
+		asOf = parsed
+	}
+
+	userID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	periods, err := h.scheduleService.GeneratePeriods(c.Request().Context(), asOf, userID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Periods generated",
+		"periods": periods,
+	})
+}
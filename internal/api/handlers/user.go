@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	userService interfaces.UserService
+}
+
+func NewUserHandler(userService interfaces.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+func (h *UserHandler) GetUser(c echo.Context) error {
+	user, err := h.userService.GetUser(c.Request().Context(), c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *UserHandler) UpdateProfile(c echo.Context) error {
+	var input struct {
+		FullName string `json:"full_name"`
+		Email    string `json:"email"`
+		Position string `json:"position"`
+		HireDate string `json:"hire_date"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request().Context(), c.Param("user_id"), input.FullName, input.Email, input.Position, input.HireDate, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *UserHandler) ChangeStatus(c echo.Context) error {
+	var input struct {
+		Status        string `json:"status"`
+		EffectiveDate string `json:"effective_date"`
+		Reason        string `json:"reason"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	user, err := h.userService.ChangeStatus(c.Request().Context(), c.Param("user_id"), input.Status, input.EffectiveDate, input.Reason, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *UserHandler) SetSalary(c echo.Context) error {
+	var input struct {
+		Amount        float64 `json:"amount"`
+		EffectiveDate string  `json:"effective_date"`
+		Reason        string  `json:"reason"`
+	}
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	adminID, err := GetUserIDFromContext(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	change, err := h.userService.SetSalary(c.Request().Context(), c.Param("user_id"), input.Amount, input.EffectiveDate, input.Reason, adminID, c.RealIP(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message":        "Salary change recorded",
+		"change_id":      change.ID,
+		"amount":         change.Amount,
+		"effective_date": change.EffectiveDate.Format("2006-01-02"),
+	})
+}
+
+func (h *UserHandler) ListSalaryChanges(c echo.Context) error {
+	changes, err := h.userService.ListSalaryChanges(c.Request().Context(), c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	history := make([]map[string]interface{}, len(changes))
+	for i, change := range changes {
+		history[i] = map[string]interface{}{
+			"change_id":      change.ID,
+			"amount":         change.Amount,
+			"effective_date": change.EffectiveDate.Format("2006-01-02"),
+			"reason":         change.Reason,
+			"created_at":     change.CreatedAt,
+			"created_by":     change.CreatedBy,
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"salary_history": history})
+}
+
+// userResponse lists the fields of user that may be shown to admins. The
+// password hash is left out.
+func userResponse(user *models.User) map[string]interface{} {
+	response := map[string]interface{}{
+		"user_id":        user.ID,
+		"username":       user.Username,
+		"role":           user.Role,
+		"salary":         user.Salary,
+		"full_name":      user.FullName,
+		"email":          user.Email,
+		"position":       user.Position,
+		"hire_date":      nil,
+		"status":         user.Status,
+		"effective_date": nil,
+		"status_reason":  user.StatusReason,
+		"department_id":  user.DepartmentID,
+		"cost_center_id": user.CostCenterID,
+		"manager_id":     user.ManagerID,
+		"sso_subject":    user.ExternalSubject,
+	}
+	if user.HireDate != nil {
+		response["hire_date"] = user.HireDate.Format("2006-01-02")
+	}
+	if user.StatusEffectiveDate != nil {
+		response["effective_date"] = user.StatusEffectiveDate.Format("2006-01-02")
+	}
+	return response
+}
@@ -0,0 +1,39 @@
+// Package actor carries how a request was authenticated through its context,
+// so audit entries can be attributed without every service passing it on.
+package actor
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+
+	"github.com/google/uuid"
+)
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey marks ctx as authenticated by the API key id.
+func WithAPIKey(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, id)
+}
+
+// APIKeyFromContext returns the API key ctx was authenticated with, if any.
+func APIKeyFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(apiKeyContextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// Attribute stamps the tenant and API key of ctx on an audit entry that is
+// written later, away from ctx. Fields already set are kept.
+func Attribute(ctx context.Context, entry *models.AuditLog) {
+	if entry.TenantID == uuid.Nil {
+		if id, ok := tenant.FromContext(ctx); ok {
+			entry.TenantID = id
+		}
+	}
+	if entry.APIKeyID == nil {
+		if id, ok := APIKeyFromContext(ctx); ok {
+			entry.APIKeyID = &id
+		}
+	}
+}
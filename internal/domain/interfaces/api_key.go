@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	FindAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	// TouchAPIKey records a use of the key. It only writes when the last
+	// recorded use is older than every, to avoid a write per request.
+	TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time, ipAddress string, every time.Duration) error
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, name string, scopes []string, expiresAt string, granted []string, adminID uuid.UUID, ipAddress, requestID string) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, adminID uuid.UUID, ipAddress, requestID string) error
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"payslip/internal/domain/models"
 	"time"
 
@@ -11,16 +12,39 @@ import (
 type AttendanceRepository interface {
 	CreatePeriod(ctx context.Context, period *models.AttendancePeriod) error
 	FindPeriodByID(ctx context.Context, id uuid.UUID) (*models.AttendancePeriod, error)
+	FindPeriods(ctx context.Context, from, to *time.Time, limit, offset int) ([]*models.AttendancePeriod, int64, error)
+	FindPeriodsContainingDate(ctx context.Context, date time.Time) ([]*models.AttendancePeriod, error)
+	FindOverlappingPeriods(ctx context.Context, start, end time.Time, excludeID uuid.UUID) ([]*models.AttendancePeriod, error)
+	UpdatePeriod(ctx context.Context, period *models.AttendancePeriod) error
+	DeletePeriod(ctx context.Context, id uuid.UUID) error
+	HasSubmissions(ctx context.Context, periodID uuid.UUID) bool
 	CreateAttendance(ctx context.Context, attendance *models.Attendance) error
+	// FindAttendanceByUserAndDate returns the user's attendance on date that
+	// has not been rejected in review.
 	FindAttendanceByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time, periodID uuid.UUID) (*models.Attendance, error)
+	FindAttendanceByID(ctx context.Context, id uuid.UUID) (*models.Attendance, error)
+	FindAttendancesByReviewStatus(ctx context.Context, status string) ([]*models.Attendance, error)
+	UpdateAttendance(ctx context.Context, attendance *models.Attendance) error
 	CreateOvertime(ctx context.Context, overtime *models.Overtime) error
 	CreateReimbursement(ctx context.Context, reimbursement *models.Reimbursement) error
 	IsPayrollProcessed(ctx context.Context, periodID uuid.UUID) bool
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+	// WithPeriodLock runs fn in a transaction holding the lock that period
+	// generation takes, so periods checked for overlap cannot change until fn
+	// commits.
+	WithPeriodLock(ctx context.Context, fn func(tx context.Context) error) error
 }
 
 type AttendanceService interface {
 	CreatePeriod(ctx context.Context, startDate, endDate string, userID uuid.UUID, ipAddress, requestID string) (*models.AttendancePeriod, error)
-	SubmitAttendance(ctx context.Context, date, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error)
+	ListPeriods(ctx context.Context, from, to string, page, pageSize int) ([]*models.AttendancePeriod, int64, error)
+	GetPeriod(ctx context.Context, periodID string) (*models.AttendancePeriod, error)
+	UpdatePeriod(ctx context.Context, periodID, startDate, endDate string, userID uuid.UUID, ipAddress, requestID string) (*models.AttendancePeriod, error)
+	DeletePeriod(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) error
+	SubmitAttendance(ctx context.Context, date, periodID string, latitude, longitude *float64, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error)
+	ListFlaggedAttendances(ctx context.Context, status string) ([]*models.Attendance, error)
+	ReviewAttendance(ctx context.Context, attendanceID, decision string, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error)
+	ImportAttendance(ctx context.Context, r io.Reader, periodID string, dryRun bool, userID uuid.UUID, ipAddress, requestID string) ([]*models.ImportRowResult, error)
 	SubmitOvertime(ctx context.Context, date string, hours float64, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Overtime, error)
 	SubmitReimbursement(ctx context.Context, amount float64, description, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Reimbursement, error)
 }
@@ -3,9 +3,102 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
 )
 
-type AuditRepository interface {
+// AuditSink is where services write their audit entries. The repository
+// writes them straight away; see services.AsyncAuditSink and AuditOutbox for
+// the others.
+type AuditSink interface {
 	Create(ctx context.Context, audit *models.AuditLog) error
+	CreateBatch(ctx context.Context, audits []*models.AuditLog) error
+}
+
+// AuditOutbox is an AuditSink that writes entries to an outbox table in the
+// caller's transaction, so they commit or roll back with the change they
+// describe. RelayAuditOutbox moves up to limit committed entries into the
+// audit log and reports how many it moved.
+type AuditOutbox interface {
+	AuditSink
+	RelayAuditOutbox(ctx context.Context, limit int) (int, error)
+}
+
+type AuditRepository interface {
+	AuditSink
+	// FindAuditLogs returns up to limit entries matching filter, newest
+	// first, starting after the entry at cursor when it is set.
+	FindAuditLogs(ctx context.Context, filter AuditFilter, cursor *AuditCursor, limit int) ([]*models.AuditLog, error)
+	// FindAuditChain returns up to limit chained entries after sequence, in
+	// chain order.
+	FindAuditChain(ctx context.Context, afterSequence int64, limit int) ([]*models.AuditLog, error)
+	// FindAuditChainGap returns the archived run of entries starting at
+	// sequence, or nil when there is none.
+	FindAuditChainGap(ctx context.Context, fromSequence int64) (*models.AuditChainGap, error)
+}
+
+// AuditRecorder writes audit entries off the request path, for events that
+// must not slow down or fail the request they describe, such as reads and
+// denied requests. The context's tenant and API key still apply.
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *models.AuditLog)
+}
+
+// AuditFilter narrows an audit log search. Zero fields match everything.
+type AuditFilter struct {
+	UserID    *uuid.UUID
+	APIKeyID  *uuid.UUID
+	TableName string
+	RecordID  *uuid.UUID
+	Action    string
+	Outcome   string
+	RequestID string
+	Field     string     // column that changed, see AuditLog.Changes
+	From      *time.Time // inclusive
+	To        *time.Time // exclusive
+}
+
+// AuditCursor is the position of the last entry on a page.
+type AuditCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// AuditQuery is an audit log search as given by the caller, before parsing.
+// From and To are dates or RFC 3339 times; a To date includes that day.
+type AuditQuery struct {
+	UserID    string
+	APIKeyID  string
+	TableName string
+	RecordID  string
+	Action    string
+	Outcome   string
+	RequestID string
+	Field     string
+	From      string
+	To        string
+}
+
+// AuditChainReport is the result of checking a tenant's audit chain.
+type AuditChainReport struct {
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	Valid        bool       `json:"valid"`
+	Checked      int64      `json:"checked"`
+	Unsigned     int64      `json:"unsigned"` // entries without a signature while a key is configured
+	Archived     int64      `json:"archived"` // entries skipped because they were archived
+	LastSequence int64      `json:"last_sequence"`
+	LastHash     string     `json:"last_hash"`
+	BrokenAt     *int64     `json:"broken_at,omitempty"` // sequence of the first bad entry
+	BrokenID     *uuid.UUID `json:"broken_id,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+}
+
+type AuditService interface {
+	SearchAuditLogs(ctx context.Context, query AuditQuery, cursor string, limit int) ([]*models.AuditLog, string, error)
+	RecordHistory(ctx context.Context, tableName, recordID, cursor string, limit int) ([]*models.AuditLog, string, error)
+	ExportAuditLogs(ctx context.Context, query AuditQuery, format string, w io.Writer, userID uuid.UUID, ipAddress, requestID string) error
+	VerifyAuditChain(ctx context.Context) (*AuditChainReport, error)
 }
@@ -0,0 +1,64 @@
+// internal/domain/interfaces/audit_archive.go
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditArchiveRepository moves audit entries out of audit_logs into archives
+// and back.
+type AuditArchiveRepository interface {
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+	// LockExpiredAuditLogs locks and returns up to limit chained entries past
+	// their retention at now, ordered by tenant and sequence. The newest
+	// entry of each chain is never returned, so the chain can be extended,
+	// and entries locked elsewhere are skipped.
+	LockExpiredAuditLogs(ctx context.Context, retention AuditRetention, now time.Time, limit int) ([]*models.AuditLog, error)
+	// ArchiveAuditLogs records archive and the chain gaps it leaves, and
+	// deletes the archived entries.
+	ArchiveAuditLogs(ctx context.Context, archive *models.AuditArchive, gaps []*models.AuditChainGap, ids []uuid.UUID) error
+	// RestoreAuditLogs writes entries back unchanged, skipping those already
+	// present, removes the gaps of the archive and marks it restored. It
+	// returns the number of entries written.
+	RestoreAuditLogs(ctx context.Context, archiveID uuid.UUID, entries []*models.AuditLog, at time.Time) (int, error)
+}
+
+// AuditRetention says how long audit entries are kept. The first rule that
+// matches an entry applies, Default otherwise. A zero duration keeps entries
+// forever.
+type AuditRetention struct {
+	Default     time.Duration
+	Rules       []AuditRetentionRule // most specific first
+	RestoreHold time.Duration        // how long restored entries stay before they are archived again
+}
+
+type AuditRetentionRule struct {
+	TableName string // empty matches every table
+	Action    string // empty matches every action
+	Keep      time.Duration
+}
+
+// AuditArchiveManifest describes an archive and is stored next to its files.
+type AuditArchiveManifest struct {
+	ID            uuid.UUID          `json:"id"`
+	TenantID      uuid.UUID          `json:"tenant_id"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Entries       int                `json:"entries"`
+	FirstSequence int64              `json:"first_sequence"`
+	LastSequence  int64              `json:"last_sequence"`
+	Files         []AuditArchiveFile `json:"files"`
+}
+
+// AuditArchiveFile is one gzipped NDJSON file of an archive, holding the
+// entries of one UTC day.
+type AuditArchiveFile struct {
+	Path    string `json:"path"` // relative to the archive directory
+	Date    string `json:"date"` // YYYY-MM-DD
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"` // hex digest of the compressed file
+}
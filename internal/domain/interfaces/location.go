@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type LocationRepository interface {
+	CreateOfficeNetwork(ctx context.Context, network *models.OfficeNetwork) error
+	FindOfficeNetworks(ctx context.Context) ([]*models.OfficeNetwork, error)
+	DeleteOfficeNetwork(ctx context.Context, id uuid.UUID) error
+	CreateGeofence(ctx context.Context, geofence *models.Geofence) error
+	FindGeofences(ctx context.Context) ([]*models.Geofence, error)
+	DeleteGeofence(ctx context.Context, id uuid.UUID) error
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+}
+
+type LocationService interface {
+	CreateOfficeNetwork(ctx context.Context, name, cidr string, userID uuid.UUID, ipAddress, requestID string) (*models.OfficeNetwork, error)
+	ListOfficeNetworks(ctx context.Context) ([]*models.OfficeNetwork, error)
+	DeleteOfficeNetwork(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error
+	CreateGeofence(ctx context.Context, name string, latitude, longitude, radiusMeters float64, userID uuid.UUID, ipAddress, requestID string) (*models.Geofence, error)
+	ListGeofences(ctx context.Context) ([]*models.Geofence, error)
+	DeleteGeofence(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+)
+
+// LoginAttemptStore keeps failed login counters. A counter whose last failure
+// is older than the reset window starts again from zero.
+type LoginAttemptStore interface {
+	// FindLoginAttempt returns nil when key has no failures within window.
+	FindLoginAttempt(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error)
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+// LoginThrottledError is returned by UserService.Login while a username or
+// IP address is backing off or locked out.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type MFAService interface {
+	Required(ctx context.Context, user *models.User) (required, enrolled bool, err error)
+	Enroll(ctx context.Context, userID uuid.UUID, ipAddress, requestID string) (secret, uri string, err error)
+	Confirm(ctx context.Context, userID uuid.UUID, code, ipAddress, requestID string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, password, code, ipAddress, requestID string) error
+	VerifyLogin(ctx context.Context, userID uuid.UUID, code, recoveryCode, ipAddress, requestID string) (*models.User, []string, error)
+}
+
+type MFARepository interface {
+	FindCredential(ctx context.Context, userID uuid.UUID) (*models.MFACredential, error)
+	SaveCredential(ctx context.Context, credential *models.MFACredential) error
+	DeleteCredential(ctx context.Context, userID uuid.UUID) error
+	// UseStep records step as the last accepted code and reports false when
+	// a code of the same or a later step was already accepted.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.MFARecoveryCode) error
+	// UseRecoveryCode marks the unused code with hash as used and reports
+	// whether there was one.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+}
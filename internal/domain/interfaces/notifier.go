@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+)
+
+// Notifier delivers a message to a user, for example a password reset token.
+type Notifier interface {
+	Notify(ctx context.Context, user *models.User, subject, body string) error
+}
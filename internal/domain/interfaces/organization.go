@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotDirectReport is returned when a manager asks for data about a user
+// who does not report to them.
+var ErrNotDirectReport = errors.New("user is not one of your direct reports")
+
+type OrganizationRepository interface {
+	CreateDepartment(ctx context.Context, department *models.Department) error
+	FindDepartments(ctx context.Context) ([]*models.Department, error)
+	FindDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error)
+	DeleteDepartment(ctx context.Context, id uuid.UUID) error
+	CreateCostCenter(ctx context.Context, costCenter *models.CostCenter) error
+	FindCostCenters(ctx context.Context) ([]*models.CostCenter, error)
+	FindCostCenterByID(ctx context.Context, id uuid.UUID) (*models.CostCenter, error)
+	DeleteCostCenter(ctx context.Context, id uuid.UUID) error
+	FindUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdateUserOrganization(ctx context.Context, user *models.User) error
+	FindDirectReports(ctx context.Context, managerID uuid.UUID) ([]*models.User, error)
+	FindAttendancesByUsersAndPeriod(ctx context.Context, userIDs []uuid.UUID, periodID uuid.UUID) ([]*models.Attendance, error)
+	FindOvertimesByUsersAndPeriod(ctx context.Context, userIDs []uuid.UUID, periodID uuid.UUID) ([]*models.Overtime, error)
+}
+
+type OrganizationService interface {
+	CreateDepartment(ctx context.Context, name string, userID uuid.UUID, ipAddress, requestID string) (*models.Department, error)
+	ListDepartments(ctx context.Context) ([]*models.Department, error)
+	DeleteDepartment(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error
+	CreateCostCenter(ctx context.Context, code, name string, userID uuid.UUID, ipAddress, requestID string) (*models.CostCenter, error)
+	ListCostCenters(ctx context.Context) ([]*models.CostCenter, error)
+	DeleteCostCenter(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error
+	AssignUser(ctx context.Context, userIDStr, departmentID, costCenterID, managerID string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error)
+	ListDirectReports(ctx context.Context, managerID uuid.UUID) ([]*models.User, error)
+	GetTeamActivity(ctx context.Context, periodID, reportID string, managerID uuid.UUID) ([]map[string]interface{}, error)
+}
@@ -3,6 +3,7 @@ package interfaces
 import (
 	"context"
 	"payslip/internal/domain/models"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,14 +15,20 @@ type PayrollRepository interface {
 	FindAttendancesByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Attendance, error)
 	FindOvertimesByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Overtime, error)
 	FindReimbursementsByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Reimbursement, error)
-	FindEmployees(ctx context.Context) ([]*models.User, error)
+	FindEmployees(ctx context.Context, start, end time.Time) ([]*models.User, error)
 	CountAttendance(ctx context.Context, userID, periodID uuid.UUID) (int64, error)
+	// CountPendingReviews counts the flagged attendance of the period that
+	// has not been approved or rejected yet.
+	CountPendingReviews(ctx context.Context, periodID uuid.UUID) (int64, error)
 	SumOvertimeHours(ctx context.Context, userID, periodID uuid.UUID) (float64, error)
 	SumReimbursementAmount(ctx context.Context, userID, periodID uuid.UUID) (float64, error)
 	FindUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) // Added
+	FindSalaryChanges(ctx context.Context, userID uuid.UUID) ([]*models.SalaryChange, error)
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
 }
 type PayrollService interface {
 	RunPayroll(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) error
-	GeneratePayslip(ctx context.Context, periodID string, userID uuid.UUID) (map[string]interface{}, error)
-	GeneratePayrollSummary(ctx context.Context, periodID string) (map[string]interface{}, error)
+	GeneratePayslip(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error)
+	GetEmployeePayslip(ctx context.Context, periodID, employeeID string, viewerID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error)
+	GeneratePayrollSummary(ctx context.Context, periodID string, viewerID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error)
 }
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type RoleRepository interface {
+	FindRoles(ctx context.Context) ([]*models.Role, error)
+	FindRole(ctx context.Context, name string) (*models.Role, error)
+	CreateRole(ctx context.Context, role *models.Role) error
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, name string) error
+	SetPermissions(ctx context.Context, roleName string, permissions []string) error
+	FindPermissionsByRole(ctx context.Context, roleName string) ([]string, error)
+	CountUsersWithRole(ctx context.Context, roleName string) (int64, error)
+	SeedDefaultRoles(ctx context.Context) error
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+}
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]*models.Role, error)
+	CreateRole(ctx context.Context, name, description string, permissions []string, userID uuid.UUID, ipAddress, requestID string) (*models.Role, error)
+	UpdateRolePermissions(ctx context.Context, name string, permissions []string, userID uuid.UUID, ipAddress, requestID string) (*models.Role, error)
+	DeleteRole(ctx context.Context, name string, userID uuid.UUID, ipAddress, requestID string) error
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *models.PaySchedule) error
+	FindSchedules(ctx context.Context, activeOnly bool) ([]*models.PaySchedule, error)
+	FindScheduleByID(ctx context.Context, id uuid.UUID) (*models.PaySchedule, error)
+	UpdateSchedule(ctx context.Context, schedule *models.PaySchedule) error
+	WithGenerationLock(ctx context.Context, fn func(tx context.Context) error) error
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+}
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, name, frequency string, dayOfMonth int, anchorDate string, leadDays *int, userID uuid.UUID, ipAddress, requestID string) (*models.PaySchedule, error)
+	ListSchedules(ctx context.Context) ([]*models.PaySchedule, error)
+	DeactivateSchedule(ctx context.Context, scheduleID string, userID uuid.UUID, ipAddress, requestID string) error
+	GeneratePeriods(ctx context.Context, asOf time.Time, userID uuid.UUID, ipAddress, requestID string) ([]*models.AttendancePeriod, error)
+}
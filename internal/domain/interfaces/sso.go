@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// OIDCIdentity is the verified identity from an OpenID Connect ID token.
+type OIDCIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string // preferred_username claim
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+// OIDCProvider runs the authorization code flow against an identity
+// provider.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error)
+	// Exchange redeems an authorization code and verifies the returned ID
+	// token, including its nonce.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error)
+}
+
+type SSORepository interface {
+	CreateOIDCLoginState(ctx context.Context, state *models.OIDCLoginState) error
+	// TakeOIDCLoginState deletes and returns a login state so it is used once.
+	TakeOIDCLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	FindUserByExternalSubject(ctx context.Context, issuer, subject string) (*models.User, error)
+	UpdateExternalIdentity(ctx context.Context, user *models.User) error
+}
+
+type SSOService interface {
+	BeginLogin(ctx context.Context) (string, error)
+	CompleteLogin(ctx context.Context, state, code, ipAddress, requestID string) (*models.User, error)
+	LinkUser(ctx context.Context, userIDStr, subject string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error)
+}
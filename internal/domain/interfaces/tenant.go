@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+)
+
+type TenantRepository interface {
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+	CreateTenant(ctx context.Context, tenant *models.Tenant) error
+	FindTenants(ctx context.Context) ([]*models.Tenant, error)
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uuid.UUID) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error
+	RevokeUserSessions(ctx context.Context, revocation *models.SessionRevocation) error
+	IsAccessTokenRevoked(ctx context.Context, jti, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
+}
+
+// SessionRevoker invalidates every access and refresh token issued to a user
+// so far, see auth.JWTService.
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID, adminID uuid.UUID, ipAddress, requestID string) error
+}
@@ -3,16 +3,44 @@ package interfaces
 
 import (
 	"context"
+	"errors"
+	"io"
 	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// ErrUsernameTaken is returned when a new user's username is already used in
+// any tenant. Usernames are unique across tenants, since login does not ask
+// for one.
+var ErrUsernameTaken = errors.New("username already exists")
+
 type UserService interface {
-	Register(ctx context.Context, username, password, role, adminIDStr, ipAddress, requestID string) (*models.User, error)
-	Login(ctx context.Context, username, password string) (*models.User, string, error)
+	Register(ctx context.Context, username, password, role string, salary float64, adminIDStr, ipAddress, requestID string) (*models.User, error)
+	ImportUsers(ctx context.Context, r io.Reader, format string, dryRun, atomic bool, adminID uuid.UUID, ipAddress, requestID string) ([]*models.ImportRowResult, error)
+	Login(ctx context.Context, username, password, ipAddress, requestID string) (*models.User, string, error)
+	ChangePassword(ctx context.Context, oldPassword, newPassword string, userID uuid.UUID, ipAddress, requestID string) error
+	RequestPasswordReset(ctx context.Context, userIDStr string, adminID uuid.UUID, ipAddress, requestID string) (time.Time, error)
+	ResetPassword(ctx context.Context, token, newPassword, ipAddress, requestID string) error
+	GetUser(ctx context.Context, userIDStr string) (*models.User, error)
+	UpdateProfile(ctx context.Context, userIDStr, fullName, email, position, hireDate string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error)
+	SetSalary(ctx context.Context, userIDStr string, amount float64, effectiveDate, reason string, adminID uuid.UUID, ipAddress, requestID string) (*models.SalaryChange, error)
+	ListSalaryChanges(ctx context.Context, userIDStr string) ([]*models.SalaryChange, error)
+	ChangeStatus(ctx context.Context, userIDStr, status, effectiveDate, reason string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error)
 }
 
 type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	CreateSalaryChange(ctx context.Context, change *models.SalaryChange) error
+	FindSalaryChanges(ctx context.Context, userID uuid.UUID) ([]*models.SalaryChange, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, hash string, updatedBy uuid.UUID) error
+	CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	FindPasswordResetTokenByHash(ctx context.Context, hash string) (*models.PasswordResetToken, error)
+	UsePasswordResetToken(ctx context.Context, id uuid.UUID) (bool, error)
+	ExpireUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) error
 	WithTransaction(ctx context.Context, fn func(tx context.Context) error) error
 }
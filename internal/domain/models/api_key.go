@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, so keys can be told apart from JWTs and
+// found by secret scanners.
+const APIKeyPrefix = "psk_"
+
+// APIKey lets a script call the API without a user session. Only the SHA-256
+// hex digest of the key is stored; Prefix keeps its first characters so
+// admins can recognise it. Scopes is a comma-separated list of permissions.
+type APIKey struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID   uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Name       string    `gorm:"not null;size:100"`
+	Prefix     string    `gorm:"not null;size:16"`
+	KeyHash    string    `gorm:"not null;uniqueIndex;size:64"`
+	Scopes     string    `gorm:"not null;type:text"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:45"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	CreatedBy  uuid.UUID
+}
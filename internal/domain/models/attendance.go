@@ -8,6 +8,7 @@ import (
 
 type AttendancePeriod struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
 	StartDate time.Time `gorm:"not null;type:date"`
 	EndDate   time.Time `gorm:"not null;type:date"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
@@ -17,19 +18,27 @@ type AttendancePeriod struct {
 }
 
 type Attendance struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserID    uuid.UUID `gorm:"not null"`
-	Date      time.Time `gorm:"not null;type:date"`
-	PeriodID  uuid.UUID `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
-	CreatedBy uuid.UUID
-	UpdatedBy uuid.UUID
-	IPAddress string `gorm:"size:45"`
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	UserID       uuid.UUID `gorm:"not null"`
+	Date         time.Time `gorm:"not null;type:date"`
+	PeriodID     uuid.UUID `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	CreatedBy    uuid.UUID
+	UpdatedBy    uuid.UUID
+	IPAddress    string `gorm:"size:45"`
+	Latitude     *float64
+	Longitude    *float64
+	ReviewStatus string `gorm:"size:20"` // '' when not flagged, otherwise 'pending', 'approved' or 'rejected'
+	FlagReason   string `gorm:"size:255"`
+	ReviewedBy   *uuid.UUID
+	ReviewedAt   *time.Time
 }
 
 type Overtime struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
 	UserID    uuid.UUID `gorm:"not null"`
 	Date      time.Time `gorm:"not null;type:date"`
 	Hours     float64   `gorm:"not null"`
@@ -43,6 +52,7 @@ type Overtime struct {
 
 type Reimbursement struct {
 	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID    uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
 	UserID      uuid.UUID `gorm:"not null"`
 	Amount      float64   `gorm:"not null"`
 	Description string    `gorm:"not null;type:text"`
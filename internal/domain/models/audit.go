@@ -1,19 +1,156 @@
 package models
 
 import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type AuditLog struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Action    string    `gorm:"not null;size:100"`
-	TableName string    `gorm:"not null;size:50"`
-	RecordID  uuid.UUID
-	UserID    uuid.UUID
-	IPAddress string    `gorm:"size:45"`
-	RequestID string    `gorm:"size:36"`
-	Details   string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID        uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_audit_logs_chain;default:'00000000-0000-0000-0000-000000000001'"`
+	Action    string       `gorm:"not null;size:100"`
+	Outcome   string       `gorm:"not null;size:20;default:success;index"` // 'success', 'failure' or 'denied'
+	TableName string       `gorm:"not null;size:50;index:idx_audit_logs_record"`
+	RecordID  uuid.UUID    `gorm:"index:idx_audit_logs_record"`
+	UserID    uuid.UUID    `gorm:"index"`
+	APIKeyID  *uuid.UUID   `gorm:"type:uuid;index"` // set when the request was made with an API key
+	IPAddress string       `gorm:"size:45"`
+	RequestID string       `gorm:"size:36;index"`
+	Details   string       `gorm:"type:text"`
+	Changes   AuditChanges `gorm:"type:jsonb"` // fields of the record changed by this action, nil when none
+	Snapshot  AuditValues  `gorm:"type:jsonb"` // the record after the change, empty once deleted and nil when unchanged
+	CreatedAt time.Time    `gorm:"autoCreateTime;index"`
+	Sequence  *int64       `gorm:"uniqueIndex:idx_audit_logs_chain"` // position in the tenant's hash chain, from 1
+	PrevHash  string       `gorm:"size:64"`                          // Hash of the entry before, empty for the first
+	Hash      string       `gorm:"size:64"`
+	Signature string       `gorm:"size:100"` // base64 Ed25519 signature of Hash, empty when unsigned
+	// RestoredAt is set on entries brought back from an archive. It is not
+	// part of the hash.
+	RestoredAt *time.Time
+}
+
+// ComputeHash returns the SHA-256 hex digest of the entry's contents and its
+// place in the chain. CreatedAt counts to the microsecond, as stored.
+func (a *AuditLog) ComputeHash() (string, error) {
+	var sequence int64
+	if a.Sequence != nil {
+		sequence = *a.Sequence
+	}
+	apiKeyID := ""
+	if a.APIKeyID != nil {
+		apiKeyID = a.APIKeyID.String()
+	}
+	changes, err := json.Marshal(a.Changes)
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := json.Marshal(a.Snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	// A JSON array keeps the fields apart whatever they contain.
+	fields := []string{
+		strconv.FormatInt(sequence, 10),
+		a.PrevHash,
+		a.ID.String(),
+		a.TenantID.String(),
+		a.Action,
+		a.TableName,
+		a.RecordID.String(),
+		a.UserID.String(),
+		apiKeyID,
+		a.IPAddress,
+		a.RequestID,
+		a.Details,
+		string(changes),
+		string(snapshot),
+		a.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}
+	// Outcome came later; leaving out the default keeps older hashes valid.
+	if a.Outcome != "" && a.Outcome != AuditOutcomeSuccess {
+		fields = append(fields, a.Outcome)
+	}
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Audit outcomes.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// AuditOutboxEntry is an audit entry written in the transaction of the change
+// it describes, waiting to be moved into the audit log, where it is chained.
+// Its Changes and Snapshot are taken when it enters the outbox.
+type AuditOutboxEntry struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"` // the ID of Entry
+	Entry     AuditLog  `gorm:"serializer:json;type:jsonb;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+// AuditChange is the value of one column before and after a change. Old is
+// nil for new records and New is nil for deleted ones.
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditChanges maps column names to their change.
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	return jsonValue(c, c == nil)
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// AuditValues maps column names to values.
+type AuditValues map[string]interface{}
+
+func (v AuditValues) Value() (driver.Value, error) {
+	return jsonValue(v, v == nil)
+}
+
+func (v *AuditValues) Scan(value interface{}) error {
+	return scanJSON(value, v)
+}
+
+func jsonValue(v interface{}, isNil bool) (driver.Value, error) {
+	if isNil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func scanJSON(value, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", value)
+	}
 }
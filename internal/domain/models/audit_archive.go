@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditArchive is a batch of one tenant's audit entries moved out of
+// audit_logs into gzipped NDJSON files. Manifest is the path of its manifest
+// file, relative to the archive directory.
+type AuditArchive struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID      uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Manifest      string    `gorm:"not null;size:255"`
+	Entries       int       `gorm:"not null"`
+	FirstSequence int64     `gorm:"not null"`
+	LastSequence  int64     `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	RestoredAt    *time.Time
+}
+
+// AuditChainGap stands in for a run of archived entries in a tenant's hash
+// chain, so the chain can still be verified without them. LastHash is the
+// Hash of the entry at ToSequence, which the next entry links to.
+type AuditChainGap struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_audit_chain_gaps_start;default:'00000000-0000-0000-0000-000000000001'"`
+	FromSequence int64     `gorm:"not null;uniqueIndex:idx_audit_chain_gaps_start"`
+	ToSequence   int64     `gorm:"not null"`
+	LastHash     string    `gorm:"not null;size:64"`
+	ArchiveID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
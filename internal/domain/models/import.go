@@ -0,0 +1,10 @@
+package models
+
+// ImportRowResult reports the outcome of a single row of a bulk import.
+type ImportRowResult struct {
+	Row      int    `json:"row"`
+	Key      string `json:"key"`
+	Status   string `json:"status"` // 'valid', 'created' or 'error'
+	Error    string `json:"error,omitempty"`
+	RecordID string `json:"record_id,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OfficeNetwork struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Name      string    `gorm:"not null;size:100"`
+	CIDR      string    `gorm:"not null;size:50"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	CreatedBy uuid.UUID
+	UpdatedBy uuid.UUID
+}
+
+type Geofence struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Name         string    `gorm:"not null;size:100"`
+	Latitude     float64   `gorm:"not null"`
+	Longitude    float64   `gorm:"not null"`
+	RadiusMeters float64   `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	CreatedBy    uuid.UUID
+	UpdatedBy    uuid.UUID
+}
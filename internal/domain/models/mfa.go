@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFACredential is a user's TOTP secret. It only protects logins once
+// ConfirmedAt is set.
+type MFACredential struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Secret       string    `gorm:"not null;size:64"` // base32, as shown to the user
+	ConfirmedAt  *time.Time
+	LastUsedStep int64     `gorm:"not null;default:0"` // time step of the last accepted code, to stop replays
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+// MFARecoveryCode is a single-use code that replaces a TOTP code when the
+// authenticator is lost. Only the SHA-256 hash of the code is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	CodeHash  string    `gorm:"not null;uniqueIndex;size:64"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
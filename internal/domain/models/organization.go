@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Department struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_departments_tenant_name;default:'00000000-0000-0000-0000-000000000001'"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_departments_tenant_name;size:100"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	CreatedBy uuid.UUID
+	UpdatedBy uuid.UUID
+}
+
+type CostCenter struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cost_centers_tenant_code;default:'00000000-0000-0000-0000-000000000001'"`
+	Code      string    `gorm:"not null;uniqueIndex:idx_cost_centers_tenant_code;size:20"`
+	Name      string    `gorm:"not null;size:100"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	CreatedBy uuid.UUID
+	UpdatedBy uuid.UUID
+}
@@ -7,14 +7,17 @@ import (
 )
 
 type Payroll struct {
-	ID                  uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	PeriodID            uuid.UUID `gorm:"not null"`
-	UserID              uuid.UUID `gorm:"not null"`
-	BaseSalary          float64   `gorm:"not null"`
-	OvertimePay         float64   `gorm:"not null"`
-	ReimbursementAmount float64   `gorm:"not null"`
-	TotalPay            float64   `gorm:"not null"`
-	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	ID                  uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID            uuid.UUID  `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	PeriodID            uuid.UUID  `gorm:"not null"`
+	UserID              uuid.UUID  `gorm:"not null"`
+	BaseSalary          float64    `gorm:"not null"`
+	OvertimePay         float64    `gorm:"not null"`
+	ReimbursementAmount float64    `gorm:"not null"`
+	TotalPay            float64    `gorm:"not null"`
+	DepartmentID        *uuid.UUID `gorm:"type:uuid"` // the user's department when payroll ran
+	CostCenterID        *uuid.UUID `gorm:"type:uuid"` // the user's cost center when payroll ran
+	CreatedAt           time.Time  `gorm:"autoCreateTime"`
 	CreatedBy           uuid.UUID
 	IPAddress           string `gorm:"size:45"`
 }
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions checked by AuthMiddleware. Roles are granted permissions
+// through RolePermission rows.
+const (
+	PermUserRegister        = "user:register"
+	PermUserManage          = "user:manage"
+	PermSalaryManage        = "salary:manage"
+	PermOrgManage           = "org:manage"
+	PermTeamRead            = "team:read"
+	PermAPIKeyManage        = "apikey:manage"
+	PermAuditRead           = "audit:read"
+	PermSessionRevoke       = "session:revoke"
+	PermPasswordReset       = "user:password:reset"
+	PermRoleManage          = "role:manage"
+	PermPeriodManage        = "period:manage"
+	PermLocationManage      = "location:manage"
+	PermAttendanceReview    = "attendance:review"
+	PermAttendanceImport    = "attendance:import"
+	PermAttendanceSubmit    = "attendance:submit"
+	PermOvertimeSubmit      = "overtime:submit"
+	PermReimbursementSubmit = "reimbursement:submit"
+	PermPayrollRun          = "payroll:run"
+	PermPayrollSummaryRead  = "payroll:summary:read"
+	PermPayslipReadSelf     = "payslip:read:self"
+	PermPayslipReadAny      = "payslip:read:any"
+)
+
+// Permissions lists every permission a role can be granted.
+var Permissions = []string{
+	PermUserRegister,
+	PermUserManage,
+	PermSalaryManage,
+	PermOrgManage,
+	PermTeamRead,
+	PermAPIKeyManage,
+	PermAuditRead,
+	PermSessionRevoke,
+	PermPasswordReset,
+	PermRoleManage,
+	PermPeriodManage,
+	PermLocationManage,
+	PermAttendanceReview,
+	PermAttendanceImport,
+	PermAttendanceSubmit,
+	PermOvertimeSubmit,
+	PermReimbursementSubmit,
+	PermPayrollRun,
+	PermPayrollSummaryRead,
+	PermPayslipReadSelf,
+	PermPayslipReadAny,
+}
+
+// DefaultRolePermissions is seeded for every tenant, on migration and when a
+// tenant is created, for roles that do not exist yet. Later changes made
+// through the role endpoints are kept.
+var DefaultRolePermissions = map[string][]string{
+	"admin": {
+		PermUserRegister,
+		PermUserManage,
+		PermSalaryManage,
+		PermOrgManage,
+		PermTeamRead,
+		PermAPIKeyManage,
+		PermAuditRead,
+		PermSessionRevoke,
+		PermPasswordReset,
+		PermRoleManage,
+		PermPeriodManage,
+		PermLocationManage,
+		PermAttendanceReview,
+		PermAttendanceImport,
+		PermPayrollRun,
+		PermPayrollSummaryRead,
+		PermPayslipReadAny,
+	},
+	"employee": {
+		PermAttendanceSubmit,
+		PermOvertimeSubmit,
+		PermReimbursementSubmit,
+		PermPayslipReadSelf,
+		PermTeamRead,
+	},
+}
+
+// Role is defined per tenant, so tenants name and grant their roles
+// independently.
+type Role struct {
+	TenantID    uuid.UUID        `gorm:"type:uuid;primaryKey;default:'00000000-0000-0000-0000-000000000001'"`
+	Name        string           `gorm:"primaryKey;size:20"`
+	Description string           `gorm:"size:255"`
+	Permissions []RolePermission `gorm:"foreignKey:TenantID,RoleName;references:TenantID,Name"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime"`
+	CreatedBy   uuid.UUID
+	UpdatedBy   uuid.UUID
+}
+
+type RolePermission struct {
+	TenantID   uuid.UUID `gorm:"type:uuid;primaryKey;default:'00000000-0000-0000-0000-000000000001'"`
+	RoleName   string    `gorm:"primaryKey;size:20"`
+	Permission string    `gorm:"primaryKey;size:100"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SalaryChange sets a user's monthly salary from EffectiveDate on. The
+// earliest change also covers the days before it.
+type SalaryChange struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID      uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	UserID        uuid.UUID `gorm:"not null;index:idx_salary_user_date"`
+	Amount        float64   `gorm:"not null"`
+	EffectiveDate time.Time `gorm:"type:date;not null;index:idx_salary_user_date"`
+	Reason        string    `gorm:"size:255"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	CreatedBy     uuid.UUID
+}
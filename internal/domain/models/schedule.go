@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaySchedule struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID   uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Name       string    `gorm:"not null;size:100"`
+	Frequency  string    `gorm:"not null;size:20"` // 'monthly', 'semi_monthly', 'biweekly' or 'weekly'
+	DayOfMonth int       // first day of a monthly period (1-28)
+	AnchorDate time.Time `gorm:"type:date"` // first day of any weekly or biweekly period
+	LeadDays   int       `gorm:"not null"`
+	Active     bool      `gorm:"not null;default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	CreatedBy  uuid.UUID
+	UpdatedBy  uuid.UUID
+}
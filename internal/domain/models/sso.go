@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// OIDCLoginState holds the PKCE verifier and nonce of a single sign-on login
+// between the redirect to the identity provider and its callback. StateHash
+// is the SHA-256 hex digest of the state parameter.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"primaryKey;size:64"`
+	CodeVerifier string    `gorm:"not null;size:128"`
+	Nonce        string    `gorm:"not null;size:64"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	FamilyID  uuid.UUID `gorm:"not null;index"` // shared by every token rotated from the same login
+	TokenHash string    `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	IPAddress string    `gorm:"size:45"`
+}
+
+// RevokedToken denies one access token of UserID until it expires.
+type RevokedToken struct {
+	JTI       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	UserID    uuid.UUID
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	CreatedBy uuid.UUID
+}
+
+// SessionRevocation invalidates every access token of a user issued before
+// RevokedBefore.
+type SessionRevocation struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RevokedBefore time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+	UpdatedBy     uuid.UUID
+}
+
+// PasswordResetToken is a one-time token an admin issues so a user can set a
+// new password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	CreatedBy uuid.UUID
+}
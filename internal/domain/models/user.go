@@ -8,13 +8,50 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Username  string    `gorm:"unique;not null;size:50"`
-	Password  string    `gorm:"not null;size:100"`
-	Role      string    `gorm:"not null;size:20"` // 'employee' or 'admin'
-	Salary    float64   `gorm:"default:0"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
-	CreatedBy uuid.UUID
-	UpdatedBy uuid.UUID
+	ID                  uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TenantID            uuid.UUID  `gorm:"type:uuid;not null;index;default:'00000000-0000-0000-0000-000000000001'"`
+	Username            string     `gorm:"unique;not null;size:50"`
+	Password            string     `gorm:"not null;size:100"`
+	Role                string     `gorm:"not null;size:20"` // 'employee' or 'admin'
+	Salary              float64    `gorm:"default:0"`
+	FullName            string     `gorm:"size:100"`
+	Email               string     `gorm:"size:100"`
+	Position            string     `gorm:"size:100"`
+	HireDate            *time.Time `gorm:"type:date"`
+	Status              string     `gorm:"not null;size:20;default:active"` // 'active', 'deactivated' or 'terminated'
+	StatusEffectiveDate *time.Time `gorm:"type:date;index"`                 // first inactive day, nil while active
+	StatusReason        string     `gorm:"size:255"`
+	DepartmentID        *uuid.UUID `gorm:"type:uuid;index"`
+	CostCenterID        *uuid.UUID `gorm:"type:uuid;index"`
+	ManagerID           *uuid.UUID `gorm:"type:uuid;index"`
+	ExternalIssuer      string     `gorm:"size:255;uniqueIndex:idx_users_external_identity"` // OIDC issuer of ExternalSubject
+	ExternalSubject     *string    `gorm:"size:255;uniqueIndex:idx_users_external_identity"` // OIDC sub claim, nil for local-only users
+	CreatedAt           time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime"`
+	CreatedBy           uuid.UUID
+	UpdatedBy           uuid.UUID
+}
+
+// User statuses.
+const (
+	UserStatusActive      = "active"
+	UserStatusDeactivated = "deactivated"
+	UserStatusTerminated  = "terminated"
+)
+
+// ActiveOn reports whether the user may log in and is paid on date.
+func (u *User) ActiveOn(date time.Time) bool {
+	if u.StatusEffectiveDate == nil {
+		return true
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Before(*u.StatusEffectiveDate)
+}
+
+// LoginAttempt counts consecutive failed logins for a username or an IP
+// address. Key is prefixed with "user:" or "ip:".
+type LoginAttempt struct {
+	Key           string    `gorm:"column:attempt_key;primaryKey;size:120"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null;index"`
 }
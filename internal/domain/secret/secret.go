@@ -0,0 +1,16 @@
+// Package secret hashes the bearer secrets the service hands out, such as
+// refresh tokens, API keys, password reset tokens, MFA recovery codes and
+// login states. Only the hash is stored, and every package issuing or
+// checking a secret uses Hash so the two always agree.
+package secret
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Hash returns the hex-encoded SHA-256 of s.
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyService struct {
+	apiKeyRepo interfaces.APIKeyRepository
+	auditSink  interfaces.AuditSink
+	defaultTTL time.Duration
+}
+
+func NewAPIKeyService(apiKeyRepo interfaces.APIKeyRepository, auditSink interfaces.AuditSink, defaultTTL time.Duration) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, auditSink: auditSink, defaultTTL: defaultTTL}
+}
+
+// CreateAPIKey issues a key limited to scopes, which must all be among the
+// granted permissions of the admin creating it. expiresAt is a date or an
+// RFC 3339 time; empty uses the default lifetime. The raw key is returned
+// once and cannot be recovered later.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string, expiresAt string, granted []string, adminID uuid.UUID, ipAddress, requestID string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	scopes, err := normalizePermissions(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return nil, "", fmt.Errorf("cannot grant scope %q you do not hold", scope)
+		}
+	}
+
+	now := time.Now()
+	expires := now.Add(s.defaultTTL)
+	if expiresAt != "" {
+		expires, err = time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			expires, err = time.Parse("2006-01-02", expiresAt)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid expires_at, use YYYY-MM-DD or RFC 3339")
+		}
+	}
+	if !expires.After(now) {
+		return nil, "", fmt.Errorf("expires_at must be in the future")
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := models.APIKeyPrefix + token
+	key := &models.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    raw[:len(models.APIKeyPrefix)+8],
+		KeyHash:   secret.Hash(raw),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expires,
+		CreatedBy: adminID,
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	if err := s.auditSink.Create(ctx, &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "create",
+		TableName: "api_key",
+		RecordID:  key.ID,
+		UserID:    adminID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Created API key %s (%s) with scopes %s, expiring %s", key.Name, key.Prefix, key.Scopes, key.ExpiresAt.Format(time.RFC3339)),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, "", fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	return key, raw, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.apiKeyRepo.FindAPIKeys(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string, adminID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid API key ID: %w", err)
+	}
+	revoked, err := s.apiKeyRepo.RevokeAPIKey(ctx, parsedID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !revoked {
+		return fmt.Errorf("API key not found or already revoked")
+	}
+
+	if err := s.auditSink.Create(ctx, &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "revoke",
+		TableName: "api_key",
+		RecordID:  parsedID,
+		UserID:    adminID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Revoked API key %s", parsedID),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to log audit: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"payslip/internal/domain/interfaces"
 	"payslip/internal/domain/models"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,24 +13,20 @@ import (
 
 type AttendanceService struct {
 	attendanceRepo interfaces.AttendanceRepository
-	auditRepo      interfaces.AuditRepository
+	userRepo       interfaces.UserRepository
+	locationRepo   interfaces.LocationRepository
+	auditSink      interfaces.AuditSink
+	locationPolicy string
 }
 
-func NewAttendanceService(attendanceRepo interfaces.AttendanceRepository, auditRepo interfaces.AuditRepository) *AttendanceService {
-	return &AttendanceService{attendanceRepo: attendanceRepo, auditRepo: auditRepo}
+func NewAttendanceService(attendanceRepo interfaces.AttendanceRepository, userRepo interfaces.UserRepository, locationRepo interfaces.LocationRepository, auditSink interfaces.AuditSink, locationPolicy string) *AttendanceService {
+	return &AttendanceService{attendanceRepo: attendanceRepo, userRepo: userRepo, locationRepo: locationRepo, auditSink: auditSink, locationPolicy: locationPolicy}
 }
 
 func (s *AttendanceService) CreatePeriod(ctx context.Context, startDate, endDate string, userID uuid.UUID, ipAddress, requestID string) (*models.AttendancePeriod, error) {
-	start, err := time.Parse("2006-01-02", startDate)
+	start, end, err := parsePeriodDates(startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format: %w", err)
-	}
-	end, err := time.Parse("2006-01-02", endDate)
-	if err != nil {
-		return nil, fmt.Errorf("invalid end date format: %w", err)
-	}
-	if end.Before(start) {
-		return nil, fmt.Errorf("end date must be after start date")
+		return nil, err
 	}
 
 	period := &models.AttendancePeriod{
@@ -39,50 +36,183 @@ func (s *AttendanceService) CreatePeriod(ctx context.Context, startDate, endDate
 		CreatedBy: userID,
 		UpdatedBy: userID,
 	}
+	err = s.attendanceRepo.WithPeriodLock(ctx, func(tx context.Context) error {
+		if err := s.checkPeriodOverlap(tx, start, end, uuid.Nil); err != nil {
+			return err
+		}
+		if err := s.attendanceRepo.CreatePeriod(tx, period); err != nil {
+			return fmt.Errorf("failed to create period: %w", err)
+		}
 
-	err = s.attendanceRepo.CreatePeriod(ctx, period)
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "attendance_period",
+			RecordID:  period.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Created attendance period %s from %s to %s", period.ID, startDate, endDate),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create period: %w", err)
+		return nil, err
 	}
 
-	audit := &models.AuditLog{
-		ID:        uuid.New(),
-		Action:    "create",
-		TableName: "attendance_period",
-		RecordID:  period.ID,
-		UserID:    userID,
-		IPAddress: ipAddress,
-		RequestID: requestID,
-		Details:   fmt.Sprintf("Created attendance period %s from %s to %s", period.ID, startDate, endDate),
-		CreatedAt: time.Now(),
-	}
-	if err := s.auditRepo.Create(ctx, audit); err != nil {
-		return nil, fmt.Errorf("failed to log audit: %w", err)
+	return period, nil
+}
+
+func (s *AttendanceService) ListPeriods(ctx context.Context, from, to string, page, pageSize int) ([]*models.AttendancePeriod, int64, error) {
+	var fromDate, toDate *time.Time
+	if from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid from date format: %w", err)
+		}
+		fromDate = &parsed
+	}
+	if to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid to date format: %w", err)
+		}
+		toDate = &parsed
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
 	}
 
-	return period, nil
+	return s.attendanceRepo.FindPeriods(ctx, fromDate, toDate, pageSize, (page-1)*pageSize)
 }
 
-func (s *AttendanceService) SubmitAttendance(ctx context.Context, date, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error) {
-	parsedDate, err := time.Parse("2006-01-02", date)
+func (s *AttendanceService) GetPeriod(ctx context.Context, periodID string) (*models.AttendancePeriod, error) {
+	parsedPeriodID, err := uuid.Parse(periodID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format: %w", err)
+		return nil, fmt.Errorf("invalid period ID: %w", err)
 	}
+	return s.attendanceRepo.FindPeriodByID(ctx, parsedPeriodID)
+}
+
+func (s *AttendanceService) UpdatePeriod(ctx context.Context, periodID, startDate, endDate string, userID uuid.UUID, ipAddress, requestID string) (*models.AttendancePeriod, error) {
 	parsedPeriodID, err := uuid.Parse(periodID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid period ID: %w", err)
 	}
+	start, end, err := parsePeriodDates(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
 
-	if s.attendanceRepo.IsPayrollProcessed(ctx, parsedPeriodID) {
-		return nil, fmt.Errorf("payroll already processed for this period")
+	var period *models.AttendancePeriod
+	err = s.attendanceRepo.WithPeriodLock(ctx, func(tx context.Context) error {
+		var err error
+		period, err = s.attendanceRepo.FindPeriodByID(tx, parsedPeriodID)
+		if err != nil {
+			return err
+		}
+		if s.attendanceRepo.IsPayrollProcessed(tx, parsedPeriodID) {
+			return fmt.Errorf("payroll already processed for this period")
+		}
+		if err := s.checkPeriodOverlap(tx, start, end, parsedPeriodID); err != nil {
+			return err
+		}
+
+		oldStart, oldEnd := period.StartDate.Format("2006-01-02"), period.EndDate.Format("2006-01-02")
+		period.StartDate = start
+		period.EndDate = end
+		period.UpdatedBy = userID
+		if err := s.attendanceRepo.UpdatePeriod(tx, period); err != nil {
+			return fmt.Errorf("failed to update period: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "update",
+			TableName: "attendance_period",
+			RecordID:  period.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Updated attendance period %s from %s - %s to %s - %s", period.ID, oldStart, oldEnd, startDate, endDate),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if parsedDate.Weekday() == time.Saturday || parsedDate.Weekday() == time.Sunday {
-		return nil, fmt.Errorf("cannot submit attendance on weekends")
+	return period, nil
+}
+
+func (s *AttendanceService) DeletePeriod(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedPeriodID, err := uuid.Parse(periodID)
+	if err != nil {
+		return fmt.Errorf("invalid period ID: %w", err)
 	}
 
-	if _, err := s.attendanceRepo.FindAttendanceByUserAndDate(ctx, userID, parsedDate, parsedPeriodID); err == nil {
-		return nil, fmt.Errorf("attendance already submitted for this date")
+	return s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		period, err := s.attendanceRepo.FindPeriodByID(tx, parsedPeriodID)
+		if err != nil {
+			return err
+		}
+		if s.attendanceRepo.IsPayrollProcessed(tx, parsedPeriodID) {
+			return fmt.Errorf("payroll already processed for this period")
+		}
+		if s.attendanceRepo.HasSubmissions(tx, parsedPeriodID) {
+			return fmt.Errorf("period has attendance, overtime or reimbursement submissions")
+		}
+
+		if err := s.attendanceRepo.DeletePeriod(tx, parsedPeriodID); err != nil {
+			return fmt.Errorf("failed to delete period: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "delete",
+			TableName: "attendance_period",
+			RecordID:  period.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Deleted attendance period %s from %s to %s", period.ID, period.StartDate.Format("2006-01-02"), period.EndDate.Format("2006-01-02")),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *AttendanceService) SubmitAttendance(ctx context.Context, date, periodID string, latitude, longitude *float64, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error) {
+	parsedDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+	parsedPeriodID, err := s.resolvePeriodID(ctx, periodID, parsedDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateAttendance(ctx, parsedDate, parsedPeriodID, userID); err != nil {
+		return nil, err
+	}
+
+	flagReason, err := s.evaluateLocation(ctx, ipAddress, latitude, longitude)
+	if err != nil {
+		return nil, err
 	}
 
 	attendance := &models.Attendance{
@@ -93,46 +223,223 @@ func (s *AttendanceService) SubmitAttendance(ctx context.Context, date, periodID
 		CreatedBy: userID,
 		UpdatedBy: userID,
 		IPAddress: ipAddress,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+	if flagReason != "" {
+		attendance.ReviewStatus = "pending"
+		attendance.FlagReason = flagReason
+	}
+
+	err = s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.attendanceRepo.CreateAttendance(tx, attendance); err != nil {
+			return fmt.Errorf("failed to submit attendance: %v", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "attendance",
+			RecordID:  attendance.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Submitted attendance for user %s on %s", userID, date),
+			CreatedAt: time.Now(),
+		}
+		if flagReason != "" {
+			audit.Details += fmt.Sprintf(" (flagged for review: %s)", flagReason)
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.attendanceRepo.CreateAttendance(ctx, attendance); err != nil {
-		return nil, fmt.Errorf("failed to submit attendance: %v", err)
+	return attendance, nil
+}
+
+func (s *AttendanceService) ListFlaggedAttendances(ctx context.Context, status string) ([]*models.Attendance, error) {
+	if status == "" {
+		status = "pending"
+	}
+	if status != "pending" && status != "approved" && status != "rejected" {
+		return nil, fmt.Errorf("status must be pending, approved or rejected")
 	}
+	return s.attendanceRepo.FindAttendancesByReviewStatus(ctx, status)
+}
 
-	audit := &models.AuditLog{
-		ID:        uuid.New(),
-		Action:    "create",
-		TableName: "attendance",
-		RecordID:  attendance.ID,
-		UserID:    userID,
-		IPAddress: ipAddress,
-		RequestID: requestID,
-		Details:   fmt.Sprintf("Submitted attendance for user %s on %s", userID, date),
-		CreatedAt: time.Now(),
+func (s *AttendanceService) ReviewAttendance(ctx context.Context, attendanceID, decision string, userID uuid.UUID, ipAddress, requestID string) (*models.Attendance, error) {
+	parsedID, err := uuid.Parse(attendanceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attendance ID: %w", err)
+	}
+	decision = strings.ToLower(decision)
+	if decision != "approved" && decision != "rejected" {
+		return nil, fmt.Errorf("decision must be approved or rejected")
+	}
+
+	attendance, err := s.attendanceRepo.FindAttendanceByID(ctx, parsedID)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.ReviewStatus != "pending" {
+		return nil, fmt.Errorf("attendance is not pending review")
 	}
-	if err := s.auditRepo.Create(ctx, audit); err != nil {
-		return nil, fmt.Errorf("failed to log audit: %v", err)
+	if s.attendanceRepo.IsPayrollProcessed(ctx, attendance.PeriodID) {
+		return nil, fmt.Errorf("payroll already processed for this period")
+	}
+
+	now := time.Now()
+	attendance.ReviewStatus = decision
+	attendance.ReviewedBy = &userID
+	attendance.ReviewedAt = &now
+	attendance.UpdatedBy = userID
+	err = s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.attendanceRepo.UpdateAttendance(tx, attendance); err != nil {
+			return fmt.Errorf("failed to review attendance: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "review",
+			TableName: "attendance",
+			RecordID:  attendance.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Marked flagged attendance %s as %s", attendance.ID, decision),
+			CreatedAt: now,
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return attendance, nil
 }
 
+// resolvePeriodID parses periodID when it is given. Otherwise it returns the
+// single period whose dates contain date.
+func (s *AttendanceService) resolvePeriodID(ctx context.Context, periodID string, date time.Time) (uuid.UUID, error) {
+	if periodID != "" {
+		parsedPeriodID, err := uuid.Parse(periodID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid period ID: %w", err)
+		}
+		return parsedPeriodID, nil
+	}
+
+	periods, err := s.attendanceRepo.FindPeriodsContainingDate(ctx, date)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	switch len(periods) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("no attendance period covers %s", date.Format("2006-01-02"))
+	case 1:
+		return periods[0].ID, nil
+	default:
+		return uuid.Nil, fmt.Errorf("more than one attendance period covers %s, specify period_id", date.Format("2006-01-02"))
+	}
+}
+
+func (s *AttendanceService) checkPeriodOverlap(ctx context.Context, start, end time.Time, excludeID uuid.UUID) error {
+	overlapping, err := s.attendanceRepo.FindOverlappingPeriods(ctx, start, end, excludeID)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		p := overlapping[0]
+		return fmt.Errorf("period overlaps existing period %s from %s to %s", p.ID, p.StartDate.Format("2006-01-02"), p.EndDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func parsePeriodDates(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date format: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date format: %w", err)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date must be after start date")
+	}
+	return start, end, nil
+}
+
+func (s *AttendanceService) validateAttendance(ctx context.Context, date time.Time, periodID, userID uuid.UUID) error {
+	if s.attendanceRepo.IsPayrollProcessed(ctx, periodID) {
+		return fmt.Errorf("payroll already processed for this period")
+	}
+
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return fmt.Errorf("cannot submit attendance on weekends")
+	}
+
+	if _, err := s.attendanceRepo.FindAttendanceByUserAndDate(ctx, userID, date, periodID); err == nil {
+		return fmt.Errorf("attendance already submitted for this date")
+	}
+	return nil
+}
+
+func validateOvertimeHours(hours float64) error {
+	if hours > 3 {
+		return fmt.Errorf("overtime cannot exceed 3 hours per day")
+	}
+	return nil
+}
+
+// evaluateLocation applies the configured location policy. It returns a flag
+// reason when the attendance should be queued for review, or an error when it
+// must be rejected outright.
+func (s *AttendanceService) evaluateLocation(ctx context.Context, ipAddress string, latitude, longitude *float64) (string, error) {
+	if s.locationRepo == nil || s.locationPolicy == "" || s.locationPolicy == LocationPolicyOff {
+		return "", nil
+	}
+
+	networks, err := s.locationRepo.FindOfficeNetworks(ctx)
+	if err != nil {
+		return "", err
+	}
+	geofences, err := s.locationRepo.FindGeofences(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	reason := checkLocation(networks, geofences, ipAddress, latitude, longitude)
+	if reason != "" && s.locationPolicy == LocationPolicyReject {
+		return "", fmt.Errorf("attendance rejected: %s", reason)
+	}
+	return reason, nil
+}
+
 func (s *AttendanceService) SubmitOvertime(ctx context.Context, date string, hours float64, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Overtime, error) {
 	parsedDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
 		return nil, fmt.Errorf("invalid date format: %w", err)
 	}
-	parsedPeriodID, err := uuid.Parse(periodID)
+	parsedPeriodID, err := s.resolvePeriodID(ctx, periodID, parsedDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid period ID: %w", err)
+		return nil, err
 	}
 
 	if s.attendanceRepo.IsPayrollProcessed(ctx, parsedPeriodID) {
 		return nil, fmt.Errorf("payroll already processed for this period")
 	}
 
-	if hours > 3 {
-		return nil, fmt.Errorf("overtime cannot exceed 3 hours per day")
+	if err := validateOvertimeHours(hours); err != nil {
+		return nil, err
 	}
 
 	overtime := &models.Overtime{
@@ -146,32 +453,38 @@ func (s *AttendanceService) SubmitOvertime(ctx context.Context, date string, hou
 		IPAddress: ipAddress,
 	}
 
-	if err := s.attendanceRepo.CreateOvertime(ctx, overtime); err != nil {
-		return nil, fmt.Errorf("failed to submit overtime: %v", err)
-	}
+	err = s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.attendanceRepo.CreateOvertime(tx, overtime); err != nil {
+			return fmt.Errorf("failed to submit overtime: %v", err)
+		}
 
-	audit := &models.AuditLog{
-		ID:        uuid.New(),
-		Action:    "create",
-		TableName: "overtime",
-		RecordID:  overtime.ID,
-		UserID:    userID,
-		IPAddress: ipAddress,
-		RequestID: requestID,
-		Details:   fmt.Sprintf("Submitted %f hours overtime for user %s on %s", hours, userID, date),
-		CreatedAt: time.Now(),
-	}
-	if err := s.auditRepo.Create(ctx, audit); err != nil {
-		return nil, fmt.Errorf("failed to log audit: %v", err)
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "overtime",
+			RecordID:  overtime.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Submitted %f hours overtime for user %s on %s", hours, userID, date),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return overtime, nil
 }
 
 func (s *AttendanceService) SubmitReimbursement(ctx context.Context, amount float64, description, periodID string, userID uuid.UUID, ipAddress, requestID string) (*models.Reimbursement, error) {
-	parsedPeriodID, err := uuid.Parse(periodID)
+	parsedPeriodID, err := s.resolvePeriodID(ctx, periodID, time.Now().UTC().Truncate(24*time.Hour))
 	if err != nil {
-		return nil, fmt.Errorf("invalid period ID: %w", err)
+		return nil, err
 	}
 
 	if s.attendanceRepo.IsPayrollProcessed(ctx, parsedPeriodID) {
@@ -196,23 +509,29 @@ func (s *AttendanceService) SubmitReimbursement(ctx context.Context, amount floa
 		IPAddress:   ipAddress,
 	}
 
-	if err := s.attendanceRepo.CreateReimbursement(ctx, reimbursement); err != nil {
-		return nil, fmt.Errorf("failed to submit reimbursement: %v", err)
-	}
+	err = s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.attendanceRepo.CreateReimbursement(tx, reimbursement); err != nil {
+			return fmt.Errorf("failed to submit reimbursement: %v", err)
+		}
 
-	audit := &models.AuditLog{
-		ID:        uuid.New(),
-		Action:    "create",
-		TableName: "reimbursement",
-		RecordID:  reimbursement.ID,
-		UserID:    userID,
-		IPAddress: ipAddress,
-		RequestID: requestID,
-		Details:   fmt.Sprintf("Submitted reimbursement of $%f for user %s", amount, userID),
-		CreatedAt: time.Now(),
-	}
-	if err := s.auditRepo.Create(ctx, audit); err != nil {
-		return nil, fmt.Errorf("failed to log audit: %v", err)
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "reimbursement",
+			RecordID:  reimbursement.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Submitted reimbursement of $%f for user %s", amount, userID),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return reimbursement, nil
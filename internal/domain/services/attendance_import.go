@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"payslip/internal/domain/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type attendanceImportRow struct {
+	result   *models.ImportRowResult
+	userID   uuid.UUID
+	date     time.Time
+	overtime float64
+}
+
+// ImportAttendance validates every CSV row with the same rules used for
+// employee submissions and, unless dryRun is set, inserts all rows in a single
+// transaction. Nothing is written when any row fails validation.
+//
+// The CSV must have a header row with the columns username and date
+// (YYYY-MM-DD). An optional overtime_hours column (also accepted as overtime
+// or hours) records overtime for the same day.
+func (s *AttendanceService) ImportAttendance(ctx context.Context, r io.Reader, periodID string, dryRun bool, userID uuid.UUID, ipAddress, requestID string) ([]*models.ImportRowResult, error) {
+	parsedPeriodID, err := uuid.Parse(periodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period ID: %w", err)
+	}
+	if _, err := s.attendanceRepo.FindPeriodByID(ctx, parsedPeriodID); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	usernameCol, hasUsername := columns["username"]
+	dateCol, hasDate := columns["date"]
+	if !hasUsername || !hasDate {
+		return nil, fmt.Errorf("CSV header must contain username and date columns")
+	}
+	// Only an explicit overtime column is read as overtime; a badge system's
+	// hours column is usually the time worked.
+	overtimeCol := -1
+	for _, name := range []string{"overtime_hours", "overtime"} {
+		if i, ok := columns[name]; ok {
+			overtimeCol = i
+			break
+		}
+	}
+
+	var rows []*attendanceImportRow
+	var results []*models.ImportRowResult
+	seen := map[string]int{}
+	failed := 0
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		result := &models.ImportRowResult{Row: line, Status: "valid"}
+		results = append(results, result)
+		if err != nil {
+			result.Status, result.Error = "error", err.Error()
+			failed++
+			continue
+		}
+
+		row, err := s.validateImportRow(ctx, record, usernameCol, dateCol, overtimeCol, parsedPeriodID, result)
+		if err == nil {
+			if prev, ok := seen[result.Key]; ok {
+				err = fmt.Errorf("duplicate of row %d", prev)
+			} else {
+				seen[result.Key] = line
+			}
+		}
+		if err != nil {
+			result.Status, result.Error = "error", err.Error()
+			failed++
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("CSV contains no rows")
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d rows failed validation", failed, len(results))
+	}
+	if dryRun {
+		return results, nil
+	}
+
+	err = s.attendanceRepo.WithTransaction(ctx, func(tx context.Context) error {
+		for _, row := range rows {
+			if err := s.createImportedRow(tx, row, parsedPeriodID, userID, ipAddress, requestID); err != nil {
+				return fmt.Errorf("row %d: %w", row.result.Row, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, result := range results {
+			result.Status, result.RecordID = "valid", ""
+		}
+		return results, fmt.Errorf("failed to import attendance: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s *AttendanceService) validateImportRow(ctx context.Context, record []string, usernameCol, dateCol, overtimeCol int, periodID uuid.UUID, result *models.ImportRowResult) (*attendanceImportRow, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	username := field(usernameCol)
+	date := field(dateCol)
+	result.Key = username + "@" + date
+	if username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("unknown user %s", username)
+	}
+	if user.Role != "employee" {
+		return nil, fmt.Errorf("user %s is not an employee", username)
+	}
+
+	parsedDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+	if err := s.validateAttendance(ctx, parsedDate, periodID, user.ID); err != nil {
+		return nil, err
+	}
+
+	var overtime float64
+	if value := field(overtimeCol); value != "" {
+		overtime, err = strconv.ParseFloat(value, 64)
+		if err != nil || overtime < 0 {
+			return nil, fmt.Errorf("invalid overtime hours %q", value)
+		}
+		if err := validateOvertimeHours(overtime); err != nil {
+			return nil, err
+		}
+	}
+
+	return &attendanceImportRow{result: result, userID: user.ID, date: parsedDate, overtime: overtime}, nil
+}
+
+func (s *AttendanceService) createImportedRow(ctx context.Context, row *attendanceImportRow, periodID, userID uuid.UUID, ipAddress, requestID string) error {
+	attendance := &models.Attendance{
+		ID:        uuid.New(),
+		UserID:    row.userID,
+		Date:      row.date,
+		PeriodID:  periodID,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+		IPAddress: ipAddress,
+	}
+	if err := s.attendanceRepo.CreateAttendance(ctx, attendance); err != nil {
+		return fmt.Errorf("failed to create attendance: %w", err)
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "import",
+		TableName: "attendance",
+		RecordID:  attendance.ID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Imported attendance for user %s on %s", row.userID, row.date.Format("2006-01-02")),
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	if row.overtime > 0 {
+		overtime := &models.Overtime{
+			ID:        uuid.New(),
+			UserID:    row.userID,
+			Date:      row.date,
+			Hours:     row.overtime,
+			PeriodID:  periodID,
+			CreatedBy: userID,
+			UpdatedBy: userID,
+			IPAddress: ipAddress,
+		}
+		if err := s.attendanceRepo.CreateOvertime(ctx, overtime); err != nil {
+			return fmt.Errorf("failed to create overtime: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "import",
+			TableName: "overtime",
+			RecordID:  overtime.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Imported %f hours overtime for user %s on %s", row.overtime, row.userID, row.date.Format("2006-01-02")),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(ctx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+	}
+
+	row.result.Status = "created"
+	row.result.RecordID = attendance.ID.String()
+	return nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// auditExportBatch is how many entries an export reads at a time.
+const auditExportBatch = 500
+
+type AuditService struct {
+	auditRepo interfaces.AuditRepository
+	publicKey ed25519.PublicKey // verifies entry signatures, nil to skip them
+}
+
+func NewAuditService(auditRepo interfaces.AuditRepository, publicKey ed25519.PublicKey) *AuditService {
+	return &AuditService{auditRepo: auditRepo, publicKey: publicKey}
+}
+
+// SearchAuditLogs returns a page of entries matching query, newest first,
+// and the cursor of the next page, which is empty on the last page.
+func (s *AuditService) SearchAuditLogs(ctx context.Context, query interfaces.AuditQuery, cursor string, limit int) ([]*models.AuditLog, string, error) {
+	filter, err := parseAuditQuery(query)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.page(ctx, filter, cursor, limit)
+}
+
+// RecordHistory returns the entries about one record, newest first.
+func (s *AuditService) RecordHistory(ctx context.Context, tableName, recordID, cursor string, limit int) ([]*models.AuditLog, string, error) {
+	if tableName == "" {
+		return nil, "", fmt.Errorf("table is required")
+	}
+	parsedRecordID, err := uuid.Parse(recordID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid record ID: %w", err)
+	}
+	return s.page(ctx, interfaces.AuditFilter{TableName: tableName, RecordID: &parsedRecordID}, cursor, limit)
+}
+
+// ExportAuditLogs writes every entry matching query to w as "csv" or
+// "ndjson", newest first. The export itself is audited.
+func (s *AuditService) ExportAuditLogs(ctx context.Context, query interfaces.AuditQuery, format string, w io.Writer, userID uuid.UUID, ipAddress, requestID string) error {
+	filter, err := parseAuditQuery(query)
+	if err != nil {
+		return err
+	}
+
+	var write func(*models.AuditLog) error
+	flush := func() error { return nil }
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "created_at", "action", "outcome", "table_name", "record_id", "user_id", "api_key_id", "ip_address", "request_id", "details", "changes"}); err != nil {
+			return err
+		}
+		write = func(entry *models.AuditLog) error {
+			apiKeyID := ""
+			if entry.APIKeyID != nil {
+				apiKeyID = entry.APIKeyID.String()
+			}
+			changes := ""
+			if entry.Changes != nil {
+				raw, err := json.Marshal(entry.Changes)
+				if err != nil {
+					return err
+				}
+				changes = string(raw)
+			}
+			return cw.Write([]string{
+				entry.ID.String(),
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				entry.Action,
+				entry.Outcome,
+				entry.TableName,
+				entry.RecordID.String(),
+				entry.UserID.String(),
+				apiKeyID,
+				entry.IPAddress,
+				entry.RequestID,
+				entry.Details,
+				changes,
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		write = func(entry *models.AuditLog) error { return encoder.Encode(entry) }
+	default:
+		return fmt.Errorf("unsupported format %q, use csv or ndjson", format)
+	}
+
+	// Audit before streaming so an interrupted export is still recorded.
+	if err := s.auditRepo.Create(ctx, &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "export",
+		TableName: "audit_log",
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Exported audit logs as %s (%s)", format, describeAuditQuery(query)),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	var cursor *interfaces.AuditCursor
+	for {
+		entries, err := s.auditRepo.FindAuditLogs(ctx, filter, cursor, auditExportBatch)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+		if len(entries) < auditExportBatch {
+			return flush()
+		}
+		last := entries[len(entries)-1]
+		cursor = &interfaces.AuditCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// VerifyAuditChain walks the audit chain of the tenant in ctx from its first
+// entry and reports the first entry whose contents, link or signature do not
+// check out. Runs of archived entries are stepped over using the hash of
+// their last entry, kept in their chain gap. Deleting entries from the end of
+// the chain cannot be detected from the chain alone; compare LastSequence and
+// LastHash with a copy kept elsewhere.
+func (s *AuditService) VerifyAuditChain(ctx context.Context) (*interfaces.AuditChainReport, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("tenant is required")
+	}
+	report := &interfaces.AuditChainReport{TenantID: tenantID, Valid: true}
+	broken := func(entry *models.AuditLog, reason string) (*interfaces.AuditChainReport, error) {
+		report.Valid = false
+		report.BrokenAt = entry.Sequence
+		report.BrokenID = &entry.ID
+		report.Reason = reason
+		return report, nil
+	}
+
+	for {
+		entries, err := s.auditRepo.FindAuditChain(ctx, report.LastSequence, auditExportBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			for *entry.Sequence > report.LastSequence+1 {
+				gap, err := s.auditRepo.FindAuditChainGap(ctx, report.LastSequence+1)
+				if err != nil {
+					return nil, err
+				}
+				if gap == nil || gap.ToSequence >= *entry.Sequence {
+					return broken(entry, fmt.Sprintf("entries %d to %d are missing", report.LastSequence+1, *entry.Sequence-1))
+				}
+				report.Archived += gap.ToSequence - gap.FromSequence + 1
+				report.LastSequence = gap.ToSequence
+				report.LastHash = gap.LastHash
+			}
+			if entry.PrevHash != report.LastHash {
+				return broken(entry, "previous hash does not match the entry before it")
+			}
+			hash, err := entry.ComputeHash()
+			if err != nil {
+				return nil, err
+			}
+			if hash != entry.Hash {
+				return broken(entry, "contents do not match the hash")
+			}
+			if s.publicKey != nil {
+				if entry.Signature == "" {
+					report.Unsigned++
+				} else if signature, err := base64.StdEncoding.DecodeString(entry.Signature); err != nil || !ed25519.Verify(s.publicKey, []byte(entry.Hash), signature) {
+					return broken(entry, "signature is not valid")
+				}
+			}
+			report.Checked++
+			report.LastSequence = *entry.Sequence
+			report.LastHash = entry.Hash
+		}
+		if len(entries) < auditExportBatch {
+			return report, nil
+		}
+	}
+}
+
+func (s *AuditService) page(ctx context.Context, filter interfaces.AuditFilter, cursor string, limit int) ([]*models.AuditLog, string, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	after, err := decodeAuditCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra entry to learn whether there is a next page.
+	entries, err := s.auditRepo.FindAuditLogs(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+	entries = entries[:limit]
+	last := entries[limit-1]
+	return entries, encodeAuditCursor(interfaces.AuditCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+func parseAuditQuery(query interfaces.AuditQuery) (interfaces.AuditFilter, error) {
+	filter := interfaces.AuditFilter{
+		TableName: strings.TrimSpace(query.TableName),
+		Action:    strings.TrimSpace(query.Action),
+		Outcome:   strings.TrimSpace(query.Outcome),
+		RequestID: strings.TrimSpace(query.RequestID),
+		Field:     strings.TrimSpace(query.Field),
+	}
+	var err error
+	if filter.UserID, err = optionalID(query.UserID, "user"); err != nil {
+		return filter, err
+	}
+	if filter.APIKeyID, err = optionalID(query.APIKeyID, "API key"); err != nil {
+		return filter, err
+	}
+	if filter.RecordID, err = optionalID(query.RecordID, "record"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseAuditTime(query.From, false); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseAuditTime(query.To, true); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	return filter, nil
+}
+
+// parseAuditTime reads a date or an RFC 3339 time. A date used as the end of
+// a range includes the whole day.
+func parseAuditTime(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("use YYYY-MM-DD or RFC 3339")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func encodeAuditCursor(cursor interfaces.AuditCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuditCursor(cursor string) (*interfaces.AuditCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	parsedTime, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &interfaces.AuditCursor{CreatedAt: parsedTime, ID: parsedID}, nil
+}
+
+func describeAuditQuery(query interfaces.AuditQuery) string {
+	var parts []string
+	for _, field := range []struct{ name, value string }{
+		{"user_id", query.UserID},
+		{"api_key_id", query.APIKeyID},
+		{"table", query.TableName},
+		{"record_id", query.RecordID},
+		{"action", query.Action},
+		{"outcome", query.Outcome},
+		{"request_id", query.RequestID},
+		{"field", query.Field},
+		{"from", query.From},
+		{"to", query.To},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.name+"="+field.value)
+		}
+	}
+	if len(parts) == 0 {
+		return "all entries"
+	}
+	return strings.Join(parts, ", ")
+}
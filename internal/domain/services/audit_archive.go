@@ -0,0 +1,402 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// auditArchiveBatch is the number of entries archived per transaction.
+const auditArchiveBatch = 5000
+
+// AuditArchiveService moves audit entries past their retention out of the
+// database into gzipped NDJSON files, one per tenant and UTC day, with a
+// manifest holding their checksums. Archived entries leave a gap in their
+// tenant's hash chain that VerifyAuditChain accepts, and can be restored for
+// an investigation.
+type AuditArchiveService struct {
+	archiveRepo interfaces.AuditArchiveRepository
+	auditSink   interfaces.AuditSink
+	dir         string
+	retention   interfaces.AuditRetention
+}
+
+// NewAuditArchiveService parses rules, a comma-separated list of
+// table=duration, table:action=duration or *:action=duration pairs. The most
+// specific matching rule applies, defaultRetention otherwise, and a duration
+// of 0 keeps entries forever. Restored entries are kept for restoreHold
+// before they are archived again.
+func NewAuditArchiveService(archiveRepo interfaces.AuditArchiveRepository, auditSink interfaces.AuditSink, dir string, defaultRetention time.Duration, rules string, restoreHold time.Duration) (*AuditArchiveService, error) {
+	s := &AuditArchiveService{
+		archiveRepo: archiveRepo,
+		auditSink:   auditSink,
+		dir:         dir,
+		retention:   interfaces.AuditRetention{Default: defaultRetention, RestoreHold: restoreHold},
+	}
+	for _, pair := range strings.Split(rules, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		keep, err := time.ParseDuration(strings.TrimSpace(value))
+		table, action, _ := strings.Cut(strings.TrimSpace(key), ":")
+		table, action = strings.Trim(strings.TrimSpace(table), "*"), strings.Trim(strings.TrimSpace(action), "*")
+		if !ok || err != nil || keep < 0 || (table == "" && action == "") {
+			return nil, fmt.Errorf("invalid audit retention rule %q, want table[:action]=duration", pair)
+		}
+		s.retention.Rules = append(s.retention.Rules, interfaces.AuditRetentionRule{TableName: table, Action: action, Keep: keep})
+	}
+	slices.SortStableFunc(s.retention.Rules, func(a, b interfaces.AuditRetentionRule) int {
+		return retentionRuleRank(a) - retentionRuleRank(b)
+	})
+	return s, nil
+}
+
+func retentionRuleRank(rule interfaces.AuditRetentionRule) int {
+	switch {
+	case rule.TableName != "" && rule.Action != "":
+		return 0
+	case rule.TableName != "":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ArchiveExpiredAuditLogs archives every entry past its retention at now and
+// returns the manifests written. Files are written before the entries are
+// deleted, and removed again when the deletion fails.
+func (s *AuditArchiveService) ArchiveExpiredAuditLogs(ctx context.Context, now time.Time) ([]*interfaces.AuditArchiveManifest, error) {
+	var manifests []*interfaces.AuditArchiveManifest
+	for {
+		var batch []*interfaces.AuditArchiveManifest
+		var written []string
+		found := 0
+		err := s.archiveRepo.WithTransaction(ctx, func(tx context.Context) error {
+			entries, err := s.archiveRepo.LockExpiredAuditLogs(tx, s.retention, now, auditArchiveBatch)
+			if err != nil {
+				return err
+			}
+			found = len(entries)
+
+			for len(entries) > 0 {
+				// Entries come ordered by tenant.
+				n := 1
+				for n < len(entries) && entries[n].TenantID == entries[0].TenantID {
+					n++
+				}
+				manifest, err := s.archive(tx, entries[:n], now, &written)
+				if err != nil {
+					return err
+				}
+				batch = append(batch, manifest)
+				entries = entries[n:]
+			}
+			return nil
+		})
+		if err != nil {
+			for _, path := range written {
+				os.Remove(path)
+			}
+			return manifests, err
+		}
+		manifests = append(manifests, batch...)
+		if found < auditArchiveBatch {
+			return manifests, nil
+		}
+	}
+}
+
+// archive writes the entries of one tenant, ordered by sequence, to a new
+// archive and deletes them. The paths of the files written are added to
+// written.
+func (s *AuditArchiveService) archive(ctx context.Context, entries []*models.AuditLog, now time.Time, written *[]string) (*interfaces.AuditArchiveManifest, error) {
+	tenantID := entries[0].TenantID
+	manifest := &interfaces.AuditArchiveManifest{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		CreatedAt:     now.UTC(),
+		Entries:       len(entries),
+		FirstSequence: *entries[0].Sequence,
+		LastSequence:  *entries[len(entries)-1].Sequence,
+	}
+
+	byDate := map[string][]*models.AuditLog{}
+	for _, entry := range entries {
+		date := entry.CreatedAt.UTC().Format("2006-01-02")
+		byDate[date] = append(byDate[date], entry)
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+	for _, date := range dates {
+		year, rest, _ := strings.Cut(date, "-")
+		month, day, _ := strings.Cut(rest, "-")
+		file := interfaces.AuditArchiveFile{
+			Path:    filepath.Join(tenantID.String(), year, month, day, manifest.ID.String()+".ndjson.gz"),
+			Date:    date,
+			Entries: len(byDate[date]),
+		}
+		hash := sha256.New()
+		path := filepath.Join(s.dir, file.Path)
+		err := writeFileAtomic(path, func(w io.Writer) error {
+			gz := gzip.NewWriter(io.MultiWriter(w, hash))
+			encoder := json.NewEncoder(gz)
+			for _, entry := range byDate[date] {
+				if err := encoder.Encode(entry); err != nil {
+					return err
+				}
+			}
+			return gz.Close()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write audit archive: %w", err)
+		}
+		*written = append(*written, path)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write audit archive: %w", err)
+		}
+		file.Bytes = info.Size()
+		file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	manifestPath := filepath.Join(tenantID.String(), "manifests", manifest.ID.String()+".json")
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.dir, manifestPath)
+	if err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(append(raw, '\n'))
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to write audit archive manifest: %w", err)
+	}
+	*written = append(*written, path)
+
+	archive := &models.AuditArchive{
+		ID:            manifest.ID,
+		TenantID:      tenantID,
+		Manifest:      manifestPath,
+		Entries:       manifest.Entries,
+		FirstSequence: manifest.FirstSequence,
+		LastSequence:  manifest.LastSequence,
+	}
+	var gaps []*models.AuditChainGap
+	ids := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		if i > 0 && *entry.Sequence == *entries[i-1].Sequence+1 {
+			gap := gaps[len(gaps)-1]
+			gap.ToSequence = *entry.Sequence
+			gap.LastHash = entry.Hash
+			continue
+		}
+		gaps = append(gaps, &models.AuditChainGap{
+			ID:           uuid.New(),
+			TenantID:     tenantID,
+			FromSequence: *entry.Sequence,
+			ToSequence:   *entry.Sequence,
+			LastHash:     entry.Hash,
+			ArchiveID:    archive.ID,
+		})
+	}
+	if err := s.archiveRepo.ArchiveAuditLogs(ctx, archive, gaps, ids); err != nil {
+		return nil, err
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Action:    "archive",
+		TableName: "audit_log",
+		RecordID:  archive.ID,
+		Details:   fmt.Sprintf("Archived %d audit entries, %d to %d, to %s", archive.Entries, archive.FirstSequence, archive.LastSequence, manifestPath),
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return nil, fmt.Errorf("failed to log audit: %w", err)
+	}
+	return manifest, nil
+}
+
+// RestoreAuditArchive checks the files of the archive at manifestPath against
+// their checksums and the entries against their hashes, then writes the
+// entries back into the audit log. Entries still present are skipped. It
+// returns the manifest and the number of entries restored.
+func (s *AuditArchiveService) RestoreAuditArchive(ctx context.Context, manifestPath string) (*interfaces.AuditArchiveManifest, int, error) {
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read audit archive manifest: %w", err)
+	}
+	var manifest interfaces.AuditArchiveManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, 0, fmt.Errorf("invalid audit archive manifest: %w", err)
+	}
+
+	entries := make([]*models.AuditLog, 0, manifest.Entries)
+	for _, file := range manifest.Files {
+		read, err := s.readArchiveFile(file, manifest.TenantID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		entries = append(entries, read...)
+	}
+	if len(entries) != manifest.Entries {
+		return nil, 0, fmt.Errorf("archive holds %d entries, manifest lists %d", len(entries), manifest.Entries)
+	}
+
+	restored := 0
+	err = s.archiveRepo.WithTransaction(ctx, func(tx context.Context) error {
+		var err error
+		restored, err = s.archiveRepo.RestoreAuditLogs(tx, manifest.ID, entries, time.Now())
+		if err != nil {
+			return err
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			TenantID:  manifest.TenantID,
+			Action:    "restore",
+			TableName: "audit_log",
+			RecordID:  manifest.ID,
+			Details:   fmt.Sprintf("Restored %d of %d archived audit entries, %d to %d", restored, manifest.Entries, manifest.FirstSequence, manifest.LastSequence),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &manifest, restored, nil
+}
+
+func (s *AuditArchiveService) readArchiveFile(file interfaces.AuditArchiveFile, tenantID uuid.UUID) ([]*models.AuditLog, error) {
+	f, err := os.Open(filepath.Join(s.dir, file.Path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	counted := &countingReader{r: io.TeeReader(f, hash)}
+	gz, err := gzip.NewReader(counted)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*models.AuditLog
+	decoder := json.NewDecoder(gz)
+	for {
+		var entry models.AuditLog
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if entry.TenantID != tenantID {
+			return nil, fmt.Errorf("entry %s belongs to another tenant", entry.ID)
+		}
+		sum, err := entry.ComputeHash()
+		if err != nil {
+			return nil, err
+		}
+		if sum != entry.Hash {
+			return nil, fmt.Errorf("entry %s does not match its hash", entry.ID)
+		}
+		entries = append(entries, &entry)
+	}
+	if _, err := io.Copy(io.Discard, counted); err != nil {
+		return nil, err
+	}
+
+	if counted.n != file.Bytes || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return nil, fmt.Errorf("checksum does not match the manifest")
+	}
+	if len(entries) != file.Entries {
+		return nil, fmt.Errorf("holds %d entries, manifest lists %d", len(entries), file.Entries)
+	}
+	return entries, nil
+}
+
+// RunArchiver calls ArchiveExpiredAuditLogs every interval until ctx is
+// cancelled.
+func (s *AuditArchiveService) RunArchiver(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		manifests, err := s.ArchiveExpiredAuditLogs(ctx, time.Now())
+		if err != nil {
+			log.Printf("Audit archiver: %v", err)
+		}
+		if len(manifests) > 0 {
+			entries := 0
+			for _, manifest := range manifests {
+				entries += manifest.Entries
+			}
+			log.Printf("Audit archiver: archived %d audit entries in %d archives", entries, len(manifests))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// writeFileAtomic writes path through a temporary file in the same directory,
+// so a crash never leaves a partly written file under its final name.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
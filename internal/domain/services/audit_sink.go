@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"log"
+	"payslip/internal/domain/actor"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AsyncAuditSink queues audit entries and writes them to next in batches
+// from a background goroutine, every batchSize entries or every interval,
+// whichever comes first. It also serves as the AuditRecorder.
+//
+// Entries are written outside the caller's transaction: they are kept when
+// that transaction rolls back, and those still queued are lost if the process
+// dies before Close. Use the outbox sink where that is not acceptable. Their
+// Changes are taken when they are written, so a record changed twice within
+// one interval has both changes on the first entry.
+type AsyncAuditSink struct {
+	next      interfaces.AuditSink
+	queue     chan *models.AuditLog
+	batchSize int
+	interval  time.Duration
+	wg        sync.WaitGroup
+}
+
+// NewAsyncAuditSink starts the writer. Its queue holds ten batches; when it is
+// full, Create writes the entry itself rather than drop it, so entries are
+// only ever delayed.
+func NewAsyncAuditSink(next interfaces.AuditSink, batchSize int, interval time.Duration) *AsyncAuditSink {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	s := &AsyncAuditSink{
+		next:      next,
+		queue:     make(chan *models.AuditLog, batchSize*10),
+		batchSize: batchSize,
+		interval:  interval,
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+func (s *AsyncAuditSink) Create(ctx context.Context, audit *models.AuditLog) error {
+	if audit.ID == uuid.Nil {
+		audit.ID = uuid.New()
+	}
+	if audit.CreatedAt.IsZero() {
+		audit.CreatedAt = time.Now()
+	}
+	actor.Attribute(ctx, audit)
+	select {
+	case s.queue <- audit:
+		return nil
+	default:
+		// The entry already carries its tenant, if any.
+		return s.next.Create(tenant.System(ctx), audit)
+	}
+}
+
+func (s *AsyncAuditSink) CreateBatch(ctx context.Context, audits []*models.AuditLog) error {
+	for _, audit := range audits {
+		if err := s.Create(ctx, audit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record queues entry, logging rather than returning a failure to write it.
+func (s *AsyncAuditSink) Record(ctx context.Context, entry *models.AuditLog) {
+	if err := s.Create(ctx, entry); err != nil {
+		logAuditFailure(entry, err)
+	}
+}
+
+// Close writes the queued entries and stops the writer. Create must not be
+// called afterwards.
+func (s *AsyncAuditSink) Close() {
+	close(s.queue)
+	s.wg.Wait()
+}
+
+func (s *AsyncAuditSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	var batch []*models.AuditLog
+	for {
+		select {
+		case audit, ok := <-s.queue:
+			if !ok {
+				s.write(batch)
+				return
+			}
+			batch = append(batch, audit)
+			if len(batch) < s.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.write(batch)
+		batch = nil
+	}
+}
+
+// write inserts batch, falling back to one entry at a time so that one bad
+// entry does not lose the others.
+func (s *AsyncAuditSink) write(batch []*models.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	// Entries carry their own tenants.
+	ctx := tenant.System(context.Background())
+	if err := s.next.CreateBatch(ctx, batch); err == nil {
+		return
+	}
+	for _, audit := range batch {
+		if err := s.next.Create(ctx, audit); err != nil {
+			logAuditFailure(audit, err)
+		}
+	}
+}
+
+func logAuditFailure(entry *models.AuditLog, err error) {
+	log.Printf("Failed to log audit %s on %s %s: %v", entry.Action, entry.TableName, entry.RecordID, err)
+}
+
+// RunAuditOutboxRelay moves entries from the outbox into the audit log until
+// ctx is cancelled. It relays batches back to back while the outbox is full
+// and checks again every interval once it is empty.
+func RunAuditOutboxRelay(ctx context.Context, outbox interfaces.AuditOutbox, batchSize int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		moved, err := outbox.RelayAuditOutbox(ctx, batchSize)
+		if err != nil {
+			log.Printf("Audit outbox relay: %v", err)
+		}
+		if err == nil && moved == batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
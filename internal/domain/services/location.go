@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location policies applied when attendance is submitted from outside the
+// configured office networks and geofences.
+const (
+	LocationPolicyOff    = "off"
+	LocationPolicyFlag   = "flag"
+	LocationPolicyReject = "reject"
+)
+
+const earthRadiusMeters = 6371000
+
+type LocationService struct {
+	locationRepo interfaces.LocationRepository
+	auditSink    interfaces.AuditSink
+}
+
+func NewLocationService(locationRepo interfaces.LocationRepository, auditSink interfaces.AuditSink) *LocationService {
+	return &LocationService{locationRepo: locationRepo, auditSink: auditSink}
+}
+
+func (s *LocationService) CreateOfficeNetwork(ctx context.Context, name, cidr string, userID uuid.UUID, ipAddress, requestID string) (*models.OfficeNetwork, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR: %w", err)
+	}
+
+	network := &models.OfficeNetwork{
+		ID:        uuid.New(),
+		Name:      name,
+		CIDR:      ipNet.String(),
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	if err := s.locationRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.locationRepo.CreateOfficeNetwork(tx, network); err != nil {
+			return fmt.Errorf("failed to create office network: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "office_network",
+			RecordID:  network.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Created office network %s (%s)", network.Name, network.CIDR),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return network, nil
+}
+
+func (s *LocationService) ListOfficeNetworks(ctx context.Context) ([]*models.OfficeNetwork, error) {
+	return s.locationRepo.FindOfficeNetworks(ctx)
+}
+
+func (s *LocationService) DeleteOfficeNetwork(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid office network ID: %w", err)
+	}
+	return s.locationRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.locationRepo.DeleteOfficeNetwork(tx, parsedID); err != nil {
+			return fmt.Errorf("failed to delete office network: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "delete",
+			TableName: "office_network",
+			RecordID:  parsedID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Deleted office network %s", parsedID),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *LocationService) CreateGeofence(ctx context.Context, name string, latitude, longitude, radiusMeters float64, userID uuid.UUID, ipAddress, requestID string) (*models.Geofence, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	if radiusMeters <= 0 {
+		return nil, fmt.Errorf("radius must be positive")
+	}
+
+	geofence := &models.Geofence{
+		ID:           uuid.New(),
+		Name:         name,
+		Latitude:     latitude,
+		Longitude:    longitude,
+		RadiusMeters: radiusMeters,
+		CreatedBy:    userID,
+		UpdatedBy:    userID,
+	}
+	if err := s.locationRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.locationRepo.CreateGeofence(tx, geofence); err != nil {
+			return fmt.Errorf("failed to create geofence: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "geofence",
+			RecordID:  geofence.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Created geofence %s at (%f, %f) with radius %fm", geofence.Name, latitude, longitude, radiusMeters),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (s *LocationService) ListGeofences(ctx context.Context) ([]*models.Geofence, error) {
+	return s.locationRepo.FindGeofences(ctx)
+}
+
+func (s *LocationService) DeleteGeofence(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid geofence ID: %w", err)
+	}
+	return s.locationRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.locationRepo.DeleteGeofence(tx, parsedID); err != nil {
+			return fmt.Errorf("failed to delete geofence: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "delete",
+			TableName: "geofence",
+			RecordID:  parsedID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Deleted geofence %s", parsedID),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkLocation returns a non-empty reason when the submission falls outside
+// the configured office networks or geofences. An empty list of networks or
+// geofences means that check is not enforced.
+func checkLocation(networks []*models.OfficeNetwork, geofences []*models.Geofence, ipAddress string, latitude, longitude *float64) string {
+	if len(networks) > 0 && !inOfficeNetwork(networks, ipAddress) {
+		return fmt.Sprintf("IP address %s is outside office networks", ipAddress)
+	}
+	if len(geofences) > 0 {
+		if latitude == nil || longitude == nil {
+			return "location was not provided"
+		}
+		if !inGeofence(geofences, *latitude, *longitude) {
+			return fmt.Sprintf("location (%f, %f) is outside office geofences", *latitude, *longitude)
+		}
+	}
+	return ""
+}
+
+func inOfficeNetwork(networks []*models.OfficeNetwork, ipAddress string) bool {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false
+	}
+	for _, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.CIDR)
+		if err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func inGeofence(geofences []*models.Geofence, latitude, longitude float64) bool {
+	for _, g := range geofences {
+		if distanceMeters(g.Latitude, g.Longitude, latitude, longitude) <= g.RadiusMeters {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceMeters returns the great-circle distance between two points using
+// the haversine formula.
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"strings"
+	"time"
+)
+
+// LoginGuard throttles logins per username and per IP address. Each failure
+// doubles the wait before the next attempt, starting at BackoffBase and capped
+// at BackoffMax. After MaxFailures consecutive failures a username is locked
+// out for LockoutDuration. Counters reset on a successful login or once no
+// failure has happened for LockoutDuration.
+type LoginGuard struct {
+	store           interfaces.LoginAttemptStore
+	MaxFailures     int
+	LockoutDuration time.Duration
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+}
+
+func NewLoginGuard(store interfaces.LoginAttemptStore, maxFailures int, lockoutDuration, backoffBase, backoffMax time.Duration) *LoginGuard {
+	return &LoginGuard{
+		store:           store,
+		MaxFailures:     maxFailures,
+		LockoutDuration: lockoutDuration,
+		BackoffBase:     backoffBase,
+		BackoffMax:      backoffMax,
+	}
+}
+
+// Check returns a *interfaces.LoginThrottledError when either counter still
+// has to wait.
+func (g *LoginGuard) Check(ctx context.Context, username, ipAddress string) error {
+	var wait time.Duration
+	for _, key := range loginAttemptKeys(username, ipAddress) {
+		attempt, err := g.store.FindLoginAttempt(ctx, key, g.LockoutDuration)
+		if err != nil {
+			return err
+		}
+		if attempt == nil {
+			continue
+		}
+		if d := time.Until(attempt.LastFailureAt.Add(g.delay(key, attempt))); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &interfaces.LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and reports whether the username is
+// now locked out.
+func (g *LoginGuard) RecordFailure(ctx context.Context, username, ipAddress string) (bool, error) {
+	locked := false
+	for _, key := range loginAttemptKeys(username, ipAddress) {
+		attempt, err := g.store.RecordLoginFailure(ctx, key, g.LockoutDuration)
+		if err != nil {
+			return false, err
+		}
+		if strings.HasPrefix(key, "user:") && g.MaxFailures > 0 && attempt.Failures == g.MaxFailures {
+			locked = true
+		}
+	}
+	return locked, nil
+}
+
+// RecordSuccess clears the username's counter. The IP address's counter is
+// kept, so signing in to one account does not reset the backoff of a client
+// guessing the passwords of others.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, username, ipAddress string) error {
+	if err := g.store.ResetLoginAttempts(ctx, userAttemptKey(username)); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+// delay returns how long after attempt's last failure the next login is
+// allowed.
+func (g *LoginGuard) delay(key string, attempt *models.LoginAttempt) time.Duration {
+	if strings.HasPrefix(key, "user:") && g.MaxFailures > 0 && attempt.Failures >= g.MaxFailures {
+		return g.LockoutDuration
+	}
+	d := g.BackoffBase
+	for i := 1; i < attempt.Failures && d < g.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > g.BackoffMax {
+		d = g.BackoffMax
+	}
+	return d
+}
+
+func loginAttemptKeys(username, ipAddress string) []string {
+	return []string{userAttemptKey(username), "ip:" + ipAddress}
+}
+
+func userAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"payslip/internal/domain/tenant"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+type MFAService struct {
+	mfaRepo       interfaces.MFARepository
+	userRepo      interfaces.UserRepository
+	auditSink     interfaces.AuditSink
+	guard         *LoginGuard
+	issuer        string
+	enforcedRoles map[string]bool
+}
+
+// NewMFAService creates the TOTP service. enforcedRoles is a comma-separated
+// list of roles whose users cannot log in without MFA.
+func NewMFAService(mfaRepo interfaces.MFARepository, userRepo interfaces.UserRepository, auditSink interfaces.AuditSink, guard *LoginGuard, issuer, enforcedRoles string) *MFAService {
+	enforced := map[string]bool{}
+	for _, role := range strings.Split(enforcedRoles, ",") {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			enforced[role] = true
+		}
+	}
+	return &MFAService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		auditSink:     auditSink,
+		guard:         guard,
+		issuer:        issuer,
+		enforcedRoles: enforced,
+	}
+}
+
+// Required reports whether logging in as user needs a second step, and
+// whether the user already has a confirmed authenticator. A user whose role
+// enforces MFA but who has not enrolled yet enrolls during that step.
+func (s *MFAService) Required(ctx context.Context, user *models.User) (bool, bool, error) {
+	enrolled := false
+	if credential, err := s.mfaRepo.FindCredential(ctx, user.ID); err == nil {
+		enrolled = credential.ConfirmedAt != nil
+	}
+	return enrolled || s.enforcedRoles[user.Role], enrolled, nil
+}
+
+// Enroll starts enrollment with a new secret. The secret only protects
+// logins after Confirm. Calling Enroll again before confirming replaces the
+// secret.
+func (s *MFAService) Enroll(ctx context.Context, userID uuid.UUID, ipAddress, requestID string) (string, string, error) {
+	// Enrollment during login runs before the request is scoped to a tenant.
+	user, err := s.userRepo.FindByID(tenant.System(ctx), userID)
+	if err != nil {
+		return "", "", err
+	}
+	ctx = tenant.WithID(ctx, user.TenantID)
+	if credential, err := s.mfaRepo.FindCredential(ctx, userID); err == nil && credential.ConfirmedAt != nil {
+		return "", "", fmt.Errorf("MFA is already enabled")
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	if err := s.mfaRepo.SaveCredential(ctx, &models.MFACredential{UserID: userID, Secret: secret}); err != nil {
+		return "", "", fmt.Errorf("failed to save MFA credential: %w", err)
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "mfa_enroll",
+		TableName: "user",
+		RecordID:  userID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Started MFA enrollment for user %s", user.Username),
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return "", "", fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	return secret, totpURI(s.issuer, user.Username, secret), nil
+}
+
+// Confirm enables MFA once the user proves the authenticator works, and
+// returns a new set of recovery codes. The codes are shown only once.
+func (s *MFAService) Confirm(ctx context.Context, userID uuid.UUID, code, ipAddress, requestID string) ([]string, error) {
+	credential, err := s.mfaRepo.FindCredential(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("MFA enrollment has not been started")
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, fmt.Errorf("MFA is already enabled")
+	}
+	step, ok := verifyTOTP(credential.Secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid MFA code")
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	credential.ConfirmedAt = &now
+	credential.LastUsedStep = step
+
+	err = s.mfaRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.mfaRepo.SaveCredential(tx, credential); err != nil {
+			return fmt.Errorf("failed to save MFA credential: %w", err)
+		}
+		if err := s.mfaRepo.ReplaceRecoveryCodes(tx, userID, records); err != nil {
+			return fmt.Errorf("failed to save recovery codes: %w", err)
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "mfa_enable",
+			TableName: "user",
+			RecordID:  userID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Enabled MFA with %d recovery codes", len(codes)),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable removes the authenticator and recovery codes. It needs the
+// password and a current code, and is refused for roles that enforce MFA.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, password, code, ipAddress, requestID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.enforcedRoles[user.Role] {
+		return fmt.Errorf("MFA is required for role %s", user.Role)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return fmt.Errorf("password is incorrect")
+	}
+	credential, err := s.mfaRepo.FindCredential(ctx, userID)
+	if err != nil || credential.ConfirmedAt == nil {
+		return fmt.Errorf("MFA is not enabled")
+	}
+	if _, ok := verifyTOTP(credential.Secret, code, time.Now()); !ok {
+		return fmt.Errorf("invalid MFA code")
+	}
+
+	return s.mfaRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.mfaRepo.DeleteCredential(tx, userID); err != nil {
+			return fmt.Errorf("failed to delete MFA credential: %w", err)
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "mfa_disable",
+			TableName: "user",
+			RecordID:  userID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Disabled MFA for user %s", user.Username),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
+
+// VerifyLogin completes the second login step with a TOTP code or a recovery
+// code. When the user is still enrolling, a valid code confirms the
+// enrollment and the new recovery codes are returned. Failures count towards
+// the login guard like wrong passwords.
+func (s *MFAService) VerifyLogin(ctx context.Context, userID uuid.UUID, code, recoveryCode, ipAddress, requestID string) (*models.User, []string, error) {
+	user, err := s.userRepo.FindByID(tenant.System(ctx), userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid MFA token")
+	}
+	ctx = tenant.WithID(ctx, user.TenantID)
+	if err := s.guard.Check(ctx, user.Username, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
+	credential, err := s.mfaRepo.FindCredential(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("MFA enrollment has not been started")
+	}
+
+	var codes []string
+	method := "TOTP code"
+	switch {
+	case credential.ConfirmedAt == nil:
+		if recoveryCode != "" {
+			return nil, nil, fmt.Errorf("MFA enrollment has not been confirmed")
+		}
+		codes, err = s.Confirm(ctx, userID, code, ipAddress, requestID)
+		method = "enrollment"
+	case recoveryCode != "":
+		var used bool
+		used, err = s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(recoveryCode))
+		if err == nil && !used {
+			err = fmt.Errorf("invalid MFA code")
+		}
+		method = "recovery code"
+	default:
+		step, ok := verifyTOTP(credential.Secret, code, time.Now())
+		var fresh bool
+		if ok {
+			fresh, err = s.mfaRepo.UseStep(ctx, userID, step)
+		}
+		if err == nil && !fresh {
+			err = fmt.Errorf("invalid MFA code")
+		}
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "mfa_verify",
+		TableName: "user",
+		RecordID:  userID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Completed login with %s", method),
+		CreatedAt: time.Now(),
+	}
+	if err != nil {
+		if _, guardErr := s.guard.RecordFailure(ctx, user.Username, ipAddress); guardErr != nil {
+			return nil, nil, guardErr
+		}
+		audit.Action = "mfa_failed"
+		audit.Outcome = models.AuditOutcomeFailure
+		audit.Details = fmt.Sprintf("Failed MFA with %s: %v", method, err)
+	} else if guardErr := s.guard.RecordSuccess(ctx, user.Username, ipAddress); guardErr != nil {
+		return nil, nil, guardErr
+	}
+	if auditErr := s.auditSink.Create(ctx, audit); auditErr != nil {
+		return nil, nil, fmt.Errorf("failed to log audit: %w", auditErr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, codes, nil
+}
+
+// newRecoveryCodes returns recoveryCodeCount codes formatted as xxxxx-xxxxx
+// and the records holding their hashes.
+func newRecoveryCodes(userID uuid.UUID) ([]string, []*models.MFARecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]*models.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		encoded := strings.ToLower(totpEncoding.EncodeToString(raw))[:10]
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+		records[i] = &models.MFARecoveryCode{ID: uuid.New(), UserID: userID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed
+// loosely.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return secret.Hash(code)
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	orgRepo        interfaces.OrganizationRepository
+	attendanceRepo interfaces.AttendanceRepository
+	auditSink      interfaces.AuditSink
+}
+
+func NewOrganizationService(orgRepo interfaces.OrganizationRepository, attendanceRepo interfaces.AttendanceRepository, auditSink interfaces.AuditSink) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo, attendanceRepo: attendanceRepo, auditSink: auditSink}
+}
+
+func (s *OrganizationService) CreateDepartment(ctx context.Context, name string, userID uuid.UUID, ipAddress, requestID string) (*models.Department, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	department := &models.Department{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	if err := s.orgRepo.CreateDepartment(ctx, department); err != nil {
+		return nil, fmt.Errorf("failed to create department: %w", err)
+	}
+
+	if err := s.audit(ctx, "create", "department", department.ID, userID, ipAddress, requestID, fmt.Sprintf("Created department %s", department.Name)); err != nil {
+		return nil, err
+	}
+
+	return department, nil
+}
+
+func (s *OrganizationService) ListDepartments(ctx context.Context) ([]*models.Department, error) {
+	return s.orgRepo.FindDepartments(ctx)
+}
+
+func (s *OrganizationService) DeleteDepartment(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid department ID: %w", err)
+	}
+	if err := s.orgRepo.DeleteDepartment(ctx, parsedID); err != nil {
+		return fmt.Errorf("failed to delete department: %w", err)
+	}
+
+	return s.audit(ctx, "delete", "department", parsedID, userID, ipAddress, requestID, fmt.Sprintf("Deleted department %s", parsedID))
+}
+
+func (s *OrganizationService) CreateCostCenter(ctx context.Context, code, name string, userID uuid.UUID, ipAddress, requestID string) (*models.CostCenter, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	name = strings.TrimSpace(name)
+	if code == "" || name == "" {
+		return nil, fmt.Errorf("code and name are required")
+	}
+	if len(code) > 20 {
+		return nil, fmt.Errorf("code must be at most 20 characters")
+	}
+
+	costCenter := &models.CostCenter{
+		ID:        uuid.New(),
+		Code:      code,
+		Name:      name,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	if err := s.orgRepo.CreateCostCenter(ctx, costCenter); err != nil {
+		return nil, fmt.Errorf("failed to create cost center: %w", err)
+	}
+
+	if err := s.audit(ctx, "create", "cost_center", costCenter.ID, userID, ipAddress, requestID, fmt.Sprintf("Created cost center %s (%s)", costCenter.Code, costCenter.Name)); err != nil {
+		return nil, err
+	}
+
+	return costCenter, nil
+}
+
+func (s *OrganizationService) ListCostCenters(ctx context.Context) ([]*models.CostCenter, error) {
+	return s.orgRepo.FindCostCenters(ctx)
+}
+
+func (s *OrganizationService) DeleteCostCenter(ctx context.Context, id string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid cost center ID: %w", err)
+	}
+	if err := s.orgRepo.DeleteCostCenter(ctx, parsedID); err != nil {
+		return fmt.Errorf("failed to delete cost center: %w", err)
+	}
+
+	return s.audit(ctx, "delete", "cost_center", parsedID, userID, ipAddress, requestID, fmt.Sprintf("Deleted cost center %s", parsedID))
+}
+
+// AssignUser sets a user's department, cost center and manager. An empty ID
+// clears the assignment. A user cannot manage themselves, directly or
+// through their own reports.
+func (s *OrganizationService) AssignUser(ctx context.Context, userIDStr, departmentID, costCenterID, managerID string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error) {
+	parsedUserID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	user, err := s.orgRepo.FindUserByID(ctx, parsedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DepartmentID, err = optionalID(departmentID, "department")
+	if err != nil {
+		return nil, err
+	}
+	if user.DepartmentID != nil {
+		if _, err := s.orgRepo.FindDepartmentByID(ctx, *user.DepartmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.CostCenterID, err = optionalID(costCenterID, "cost center")
+	if err != nil {
+		return nil, err
+	}
+	if user.CostCenterID != nil {
+		if _, err := s.orgRepo.FindCostCenterByID(ctx, *user.CostCenterID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.ManagerID, err = optionalID(managerID, "manager")
+	if err != nil {
+		return nil, err
+	}
+	if user.ManagerID != nil {
+		if err := s.checkManagerChain(ctx, user.ID, *user.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.UpdatedBy = adminID
+	if err := s.orgRepo.UpdateUserOrganization(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to assign user: %w", err)
+	}
+
+	details := fmt.Sprintf("Assigned user %s to department %s, cost center %s, manager %s",
+		user.Username, formatOptionalID(user.DepartmentID), formatOptionalID(user.CostCenterID), formatOptionalID(user.ManagerID))
+	if err := s.audit(ctx, "update", "user", user.ID, adminID, ipAddress, requestID, details); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkManagerChain walks up from managerID and fails if it reaches userID.
+func (s *OrganizationService) checkManagerChain(ctx context.Context, userID, managerID uuid.UUID) error {
+	if managerID == userID {
+		return fmt.Errorf("a user cannot be their own manager")
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for id := &managerID; id != nil; {
+		if *id == userID {
+			return fmt.Errorf("manager assignment would create a reporting cycle")
+		}
+		if seen[*id] {
+			break
+		}
+		seen[*id] = true
+
+		manager, err := s.orgRepo.FindUserByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("manager not found: %w", err)
+		}
+		id = manager.ManagerID
+	}
+	return nil
+}
+
+func (s *OrganizationService) ListDirectReports(ctx context.Context, managerID uuid.UUID) ([]*models.User, error) {
+	return s.orgRepo.FindDirectReports(ctx, managerID)
+}
+
+// GetTeamActivity returns the attendance and overtime of a manager's direct
+// reports for a period. When reportID is set only that report is returned,
+// and it must report to managerID.
+func (s *OrganizationService) GetTeamActivity(ctx context.Context, periodID, reportID string, managerID uuid.UUID) ([]map[string]interface{}, error) {
+	parsedPeriodID, err := uuid.Parse(periodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period ID: %w", err)
+	}
+	if _, err := s.attendanceRepo.FindPeriodByID(ctx, parsedPeriodID); err != nil {
+		return nil, fmt.Errorf("period not found: %w", err)
+	}
+
+	reports, err := s.orgRepo.FindDirectReports(ctx, managerID)
+	if err != nil {
+		return nil, err
+	}
+	if reportID != "" {
+		parsedReportID, err := uuid.Parse(reportID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+		var selected []*models.User
+		for _, report := range reports {
+			if report.ID == parsedReportID {
+				selected = append(selected, report)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, interfaces.ErrNotDirectReport
+		}
+		reports = selected
+	}
+
+	userIDs := make([]uuid.UUID, len(reports))
+	for i, report := range reports {
+		userIDs[i] = report.ID
+	}
+	attendances, err := s.orgRepo.FindAttendancesByUsersAndPeriod(ctx, userIDs, parsedPeriodID)
+	if err != nil {
+		return nil, err
+	}
+	overtimes, err := s.orgRepo.FindOvertimesByUsersAndPeriod(ctx, userIDs, parsedPeriodID)
+	if err != nil {
+		return nil, err
+	}
+
+	attendanceByUser := make(map[uuid.UUID][]*models.Attendance)
+	for _, a := range attendances {
+		attendanceByUser[a.UserID] = append(attendanceByUser[a.UserID], a)
+	}
+	overtimeByUser := make(map[uuid.UUID][]*models.Overtime)
+	for _, o := range overtimes {
+		overtimeByUser[o.UserID] = append(overtimeByUser[o.UserID], o)
+	}
+
+	team := make([]map[string]interface{}, len(reports))
+	for i, report := range reports {
+		var overtimeHours float64
+		for _, o := range overtimeByUser[report.ID] {
+			overtimeHours += o.Hours
+		}
+		team[i] = map[string]interface{}{
+			"user_id":         report.ID,
+			"username":        report.Username,
+			"full_name":       report.FullName,
+			"attendance":      attendanceByUser[report.ID],
+			"attendance_days": len(attendanceByUser[report.ID]),
+			"overtime":        overtimeByUser[report.ID],
+			"overtime_hours":  overtimeHours,
+		}
+	}
+
+	return team, nil
+}
+
+func (s *OrganizationService) audit(ctx context.Context, action, tableName string, recordID, userID uuid.UUID, ipAddress, requestID, details string) error {
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    action,
+		TableName: tableName,
+		RecordID:  recordID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return fmt.Errorf("failed to log audit: %w", err)
+	}
+	return nil
+}
+
+func optionalID(id, name string) (*uuid.UUID, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s ID: %w", name, err)
+	}
+	return &parsed, nil
+}
+
+func formatOptionalID(id *uuid.UUID) string {
+	if id == nil {
+		return "none"
+	}
+	return id.String()
+}
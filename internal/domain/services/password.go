@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// PasswordPolicy validates new passwords.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	breached      map[string]bool // upper-case SHA-1 hex digests
+}
+
+// LoadBreachedPasswords reads a breached password list into the policy. Each
+// line is either a plain password or a SHA-1 hex digest, optionally followed
+// by ":count" as in the Have I Been Pwned downloads. An empty path clears the
+// list.
+func (p *PasswordPolicy) LoadBreachedPasswords(path string) error {
+	p.breached = nil
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	breached := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if digest, _, _ := strings.Cut(line, ":"); isSHA1Hex(digest) {
+			breached[strings.ToUpper(digest)] = true
+			continue
+		}
+		breached[sha1Hex(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	p.breached = breached
+	return nil
+}
+
+// Validate returns the first rule password breaks.
+func (p *PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+	// bcrypt ignores everything after 72 bytes.
+	if len(password) > 72 {
+		return fmt.Errorf("password must be at most 72 bytes long")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	switch {
+	case p.RequireUpper && !upper:
+		return fmt.Errorf("password must contain an upper-case letter")
+	case p.RequireLower && !lower:
+		return fmt.Errorf("password must contain a lower-case letter")
+	case p.RequireDigit && !digit:
+		return fmt.Errorf("password must contain a digit")
+	case p.RequireSymbol && !symbol:
+		return fmt.Errorf("password must contain a symbol")
+	}
+
+	if p.breached[sha1Hex(password)] {
+		return fmt.Errorf("password appears in a list of breached passwords")
+	}
+	return nil
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -13,11 +13,13 @@ import (
 type PayrollService struct {
 	payrollRepo    interfaces.PayrollRepository
 	attendanceRepo interfaces.AttendanceRepository
-	auditRepo      interfaces.AuditRepository
+	orgRepo        interfaces.OrganizationRepository
+	auditSink      interfaces.AuditSink
+	auditRecorder  interfaces.AuditRecorder
 }
 
-func NewPayrollService(payrollRepo interfaces.PayrollRepository, attendanceRepo interfaces.AttendanceRepository, auditRepo interfaces.AuditRepository) *PayrollService {
-	return &PayrollService{payrollRepo: payrollRepo, attendanceRepo: attendanceRepo, auditRepo: auditRepo}
+func NewPayrollService(payrollRepo interfaces.PayrollRepository, attendanceRepo interfaces.AttendanceRepository, orgRepo interfaces.OrganizationRepository, auditSink interfaces.AuditSink, auditRecorder interfaces.AuditRecorder) *PayrollService {
+	return &PayrollService{payrollRepo: payrollRepo, attendanceRepo: attendanceRepo, orgRepo: orgRepo, auditSink: auditSink, auditRecorder: auditRecorder}
 }
 
 func (s *PayrollService) RunPayroll(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) error {
@@ -26,107 +28,200 @@ func (s *PayrollService) RunPayroll(ctx context.Context, periodID string, userID
 		return fmt.Errorf("invalid period ID: %w", err)
 	}
 
-	period, err := s.attendanceRepo.FindPeriodByID(ctx, parsedPeriodID)
-	if err != nil {
-		return fmt.Errorf("period not found: %w", err)
-	}
-
-	if s.attendanceRepo.IsPayrollProcessed(ctx, parsedPeriodID) {
-		return fmt.Errorf("payroll already processed for this period")
-	}
-
-	workingDays := countWorkingDays(period.StartDate, period.EndDate)
-	totalWorkingHours := float64(workingDays * 8)
-
-	employees, err := s.payrollRepo.FindEmployees(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to find employees: %w", err)
-	}
-
-	for _, user := range employees {
-		attendanceCount, err := s.payrollRepo.CountAttendance(ctx, user.ID, parsedPeriodID)
+	return s.payrollRepo.WithTransaction(ctx, func(tx context.Context) error {
+		period, err := s.attendanceRepo.FindPeriodByID(tx, parsedPeriodID)
 		if err != nil {
-			return fmt.Errorf("failed to count attendance for user %s: %w", user.ID, err)
+			return fmt.Errorf("period not found: %w", err)
 		}
 
-		salaryPerHour := user.Salary / totalWorkingHours
-		baseSalary := salaryPerHour * float64(attendanceCount*8)
+		if s.attendanceRepo.IsPayrollProcessed(tx, parsedPeriodID) {
+			return fmt.Errorf("payroll already processed for this period")
+		}
 
-		totalOvertimeHours, err := s.payrollRepo.SumOvertimeHours(ctx, user.ID, parsedPeriodID)
+		// Flagged attendance cannot be reviewed once payroll has run, so it must
+		// be approved or rejected first.
+		pending, err := s.payrollRepo.CountPendingReviews(tx, parsedPeriodID)
 		if err != nil {
-			return fmt.Errorf("failed to sum overtime for user %s: %w", user.ID, err)
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d flagged attendance records in this period are pending review", pending)
 		}
-		overtimePay := salaryPerHour * 2 * totalOvertimeHours
 
-		totalReimbursement, err := s.payrollRepo.SumReimbursementAmount(ctx, user.ID, parsedPeriodID)
+		workingDays := countWorkingDays(period.StartDate, period.EndDate)
+		totalWorkingHours := float64(workingDays * 8)
+
+		employees, err := s.payrollRepo.FindEmployees(tx, period.StartDate, period.EndDate)
 		if err != nil {
-			return fmt.Errorf("failed to sum reimbursement for user %s: %w", user.ID, err)
+			return fmt.Errorf("failed to find employees: %w", err)
 		}
 
-		totalPay := baseSalary + overtimePay + totalReimbursement
+		// The run creates all payrolls or none, and the audit entries are written
+		// in one batch in the same transaction.
+		audits := make([]*models.AuditLog, 0, len(employees))
 
-		payroll := &models.Payroll{
-			ID:                  uuid.New(),
-			PeriodID:            parsedPeriodID,
-			UserID:              user.ID,
-			BaseSalary:          baseSalary,
-			OvertimePay:         overtimePay,
-			ReimbursementAmount: totalReimbursement,
-			TotalPay:            totalPay,
-			CreatedBy:           userID,
-			IPAddress:           ipAddress,
-		}
+		for _, user := range employees {
+			attendanceCount, err := s.payrollRepo.CountAttendance(tx, user.ID, parsedPeriodID)
+			if err != nil {
+				return fmt.Errorf("failed to count attendance for user %s: %w", user.ID, err)
+			}
 
-		if err := s.payrollRepo.CreatePayroll(ctx, payroll); err != nil {
-			return fmt.Errorf("failed to create payroll for user %s: %w", user.ID, err)
-		}
+			changes, err := s.payrollRepo.FindSalaryChanges(tx, user.ID)
+			if err != nil {
+				return fmt.Errorf("failed to find salary history for user %s: %w", user.ID, err)
+			}
+			salary := proratedSalary(changes, user.Salary, period.StartDate, period.EndDate)
+
+			salaryPerHour := salary / totalWorkingHours
+			baseSalary := salaryPerHour * float64(attendanceCount*8)
+
+			totalOvertimeHours, err := s.payrollRepo.SumOvertimeHours(tx, user.ID, parsedPeriodID)
+			if err != nil {
+				return fmt.Errorf("failed to sum overtime for user %s: %w", user.ID, err)
+			}
+			overtimePay := salaryPerHour * 2 * totalOvertimeHours
+
+			totalReimbursement, err := s.payrollRepo.SumReimbursementAmount(tx, user.ID, parsedPeriodID)
+			if err != nil {
+				return fmt.Errorf("failed to sum reimbursement for user %s: %w", user.ID, err)
+			}
 
-		audit := &models.AuditLog{
-			ID:        uuid.New(),
-			Action:    "create",
-			TableName: "payroll",
-			RecordID:  payroll.ID,
-			UserID:    userID,
-			IPAddress: ipAddress,
-			RequestID: requestID,
-			Details:   fmt.Sprintf("Processed payroll for user %s for period %s", user.ID, periodID),
-			CreatedAt: time.Now(),
+			totalPay := baseSalary + overtimePay + totalReimbursement
+
+			payroll := &models.Payroll{
+				ID:                  uuid.New(),
+				PeriodID:            parsedPeriodID,
+				UserID:              user.ID,
+				BaseSalary:          baseSalary,
+				OvertimePay:         overtimePay,
+				ReimbursementAmount: totalReimbursement,
+				TotalPay:            totalPay,
+				DepartmentID:        user.DepartmentID,
+				CostCenterID:        user.CostCenterID,
+				CreatedBy:           userID,
+				IPAddress:           ipAddress,
+			}
+
+			if err := s.payrollRepo.CreatePayroll(tx, payroll); err != nil {
+				return fmt.Errorf("failed to create payroll for user %s: %w", user.ID, err)
+			}
+
+			audits = append(audits, &models.AuditLog{
+				ID:        uuid.New(),
+				Action:    "create",
+				TableName: "payroll",
+				RecordID:  payroll.ID,
+				UserID:    userID,
+				IPAddress: ipAddress,
+				RequestID: requestID,
+				Details:   fmt.Sprintf("Processed payroll for user %s for period %s", user.ID, periodID),
+				CreatedAt: time.Now(),
+			})
 		}
-		if err := s.auditRepo.Create(ctx, audit); err != nil {
+		if err := s.auditSink.CreateBatch(tx, audits); err != nil {
 			return fmt.Errorf("failed to log audit: %w", err)
 		}
+		return nil
+	})
+}
+
+// GeneratePayslip returns the user's own payslip. The view is recorded in the
+// audit log in the background.
+func (s *PayrollService) GeneratePayslip(ctx context.Context, periodID string, userID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error) {
+	parsedPeriodID, err := uuid.Parse(periodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period ID: %w", err)
+	}
+
+	payroll, err := s.payrollRepo.FindPayrollByPeriodAndUser(ctx, parsedPeriodID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("payroll not found: %w", err)
+	}
+
+	payslip, err := s.buildPayslip(ctx, payroll)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	s.auditRecorder.Record(ctx, &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "sensitive_read",
+		TableName: "payroll",
+		RecordID:  payroll.ID,
+		UserID:    userID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Viewed own payslip for period %s", periodID),
+		CreatedAt: time.Now(),
+	})
+	return payslip, nil
 }
 
-func (s *PayrollService) GeneratePayslip(ctx context.Context, periodID string, userID uuid.UUID) (map[string]interface{}, error) {
+// GetEmployeePayslip returns any employee's payslip and records the access as
+// a sensitive read in the audit log.
+func (s *PayrollService) GetEmployeePayslip(ctx context.Context, periodID, employeeID string, viewerID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error) {
 	parsedPeriodID, err := uuid.Parse(periodID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid period ID: %w", err)
 	}
+	parsedEmployeeID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
 
-	payroll, err := s.payrollRepo.FindPayrollByPeriodAndUser(ctx, parsedPeriodID, userID)
+	employee, err := s.payrollRepo.FindUserByID(ctx, parsedEmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	payroll, err := s.payrollRepo.FindPayrollByPeriodAndUser(ctx, parsedPeriodID, parsedEmployeeID)
 	if err != nil {
 		return nil, fmt.Errorf("payroll not found: %w", err)
 	}
 
-	period, err := s.attendanceRepo.FindPeriodByID(ctx, parsedPeriodID)
+	payslip, err := s.buildPayslip(ctx, payroll)
+	if err != nil {
+		return nil, err
+	}
+	payslip["user_id"] = employee.ID
+	payslip["username"] = employee.Username
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "sensitive_read",
+		TableName: "payroll",
+		RecordID:  payroll.ID,
+		UserID:    viewerID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Viewed payslip of user %s for period %s", employee.Username, periodID),
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return nil, fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	return payslip, nil
+}
+
+func (s *PayrollService) buildPayslip(ctx context.Context, payroll *models.Payroll) (map[string]interface{}, error) {
+	periodID, userID := payroll.PeriodID, payroll.UserID
+
+	period, err := s.attendanceRepo.FindPeriodByID(ctx, periodID)
 	if err != nil {
 		return nil, fmt.Errorf("period not found: %w", err)
 	}
 
-	attendances, err := s.payrollRepo.FindAttendancesByUserAndPeriod(ctx, userID, parsedPeriodID)
+	attendances, err := s.payrollRepo.FindAttendancesByUserAndPeriod(ctx, userID, periodID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find attendances: %w", err)
 	}
 
-	overtimes, err := s.payrollRepo.FindOvertimesByUserAndPeriod(ctx, userID, parsedPeriodID)
+	overtimes, err := s.payrollRepo.FindOvertimesByUserAndPeriod(ctx, userID, periodID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find overtimes: %w", err)
 	}
 
-	reimbursements, err := s.payrollRepo.FindReimbursementsByUserAndPeriod(ctx, userID, parsedPeriodID)
+	reimbursements, err := s.payrollRepo.FindReimbursementsByUserAndPeriod(ctx, userID, periodID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find reimbursements: %w", err)
 	}
@@ -143,7 +238,9 @@ func (s *PayrollService) GeneratePayslip(ctx context.Context, periodID string, u
 	}, nil
 }
 
-func (s *PayrollService) GeneratePayrollSummary(ctx context.Context, periodID string) (map[string]interface{}, error) {
+// GeneratePayrollSummary returns every employee's pay for the period and
+// records the access as a sensitive read in the background.
+func (s *PayrollService) GeneratePayrollSummary(ctx context.Context, periodID string, viewerID uuid.UUID, ipAddress, requestID string) (map[string]interface{}, error) {
 	parsedPeriodID, err := uuid.Parse(periodID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid period ID: %w", err)
@@ -154,26 +251,155 @@ func (s *PayrollService) GeneratePayrollSummary(ctx context.Context, periodID st
 		return nil, fmt.Errorf("failed to find payrolls: %w", err)
 	}
 
+	departments, err := s.orgRepo.FindDepartments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	departmentNames := make(map[uuid.UUID]string, len(departments))
+	for _, d := range departments {
+		departmentNames[d.ID] = d.Name
+	}
+	costCenters, err := s.orgRepo.FindCostCenters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	costCenterNames := make(map[uuid.UUID]string, len(costCenters))
+	for _, c := range costCenters {
+		costCenterNames[c.ID] = c.Code + " " + c.Name
+	}
+
 	var totalPay float64
+	byDepartment := newPayrollGroups(departmentNames)
+	byCostCenter := newPayrollGroups(costCenterNames)
 	summary := make([]map[string]interface{}, len(payrolls))
 	for i, p := range payrolls {
 		user, err := s.payrollRepo.FindUserByID(ctx, p.UserID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find user %s: %w", p.UserID, err)
 		}
+
+		// Payrolls run before departments existed carry no snapshot, so
+		// fall back to the user's current assignment.
+		departmentID, costCenterID := p.DepartmentID, p.CostCenterID
+		if departmentID == nil {
+			departmentID = user.DepartmentID
+		}
+		if costCenterID == nil {
+			costCenterID = user.CostCenterID
+		}
+
 		summary[i] = map[string]interface{}{
-			"username":  user.Username,
-			"total_pay": p.TotalPay,
+			"username":       user.Username,
+			"department_id":  departmentID,
+			"cost_center_id": costCenterID,
+			"total_pay":      p.TotalPay,
 		}
 		totalPay += p.TotalPay
+		byDepartment.add(departmentID, p.TotalPay)
+		byCostCenter.add(costCenterID, p.TotalPay)
 	}
 
+	s.auditRecorder.Record(ctx, &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "sensitive_read",
+		TableName: "attendance_period",
+		RecordID:  parsedPeriodID,
+		UserID:    viewerID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Viewed payroll summary of %d employees for period %s", len(payrolls), periodID),
+		CreatedAt: time.Now(),
+	})
+
 	return map[string]interface{}{
-		"summary":       summary,
-		"total_payroll": totalPay,
+		"summary":        summary,
+		"by_department":  byDepartment.list(),
+		"by_cost_center": byCostCenter.list(),
+		"total_payroll":  totalPay,
 	}, nil
 }
 
+// payrollGroups totals payrolls per department or cost center. Payrolls
+// without an assignment are grouped under a nil ID.
+type payrollGroups struct {
+	names  map[uuid.UUID]string
+	order  []*uuid.UUID
+	totals map[uuid.UUID]*payrollGroup
+	none   *payrollGroup
+}
+
+type payrollGroup struct {
+	employees int
+	totalPay  float64
+}
+
+func newPayrollGroups(names map[uuid.UUID]string) *payrollGroups {
+	return &payrollGroups{names: names, totals: make(map[uuid.UUID]*payrollGroup)}
+}
+
+func (g *payrollGroups) add(id *uuid.UUID, pay float64) {
+	var group *payrollGroup
+	if id == nil {
+		if g.none == nil {
+			g.none = &payrollGroup{}
+			g.order = append(g.order, nil)
+		}
+		group = g.none
+	} else {
+		if g.totals[*id] == nil {
+			g.totals[*id] = &payrollGroup{}
+			g.order = append(g.order, id)
+		}
+		group = g.totals[*id]
+	}
+	group.employees++
+	group.totalPay += pay
+}
+
+func (g *payrollGroups) list() []map[string]interface{} {
+	list := make([]map[string]interface{}, len(g.order))
+	for i, id := range g.order {
+		group, name := g.none, "Unassigned"
+		if id != nil {
+			group, name = g.totals[*id], g.names[*id]
+		}
+		list[i] = map[string]interface{}{
+			"id":        id,
+			"name":      name,
+			"employees": group.employees,
+			"total_pay": group.totalPay,
+		}
+	}
+	return list
+}
+
+// proratedSalary averages the monthly salary in effect on each working day
+// between start and end, so a raise mid-period is paid from its effective
+// date. Users without salary history are paid fallback.
+func proratedSalary(changes []*models.SalaryChange, fallback float64, start, end time.Time) float64 {
+	if len(changes) == 0 {
+		return fallback
+	}
+
+	total, days := 0.0, 0
+	i := 0
+	salary := changes[0].Amount
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		for i < len(changes) && !changes[i].EffectiveDate.After(d) {
+			salary = changes[i].Amount
+			i++
+		}
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			total += salary
+			days++
+		}
+	}
+	if days == 0 {
+		return salary
+	}
+	return total / float64(days)
+}
+
 func countWorkingDays(start, end time.Time) int {
 	count := 0
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
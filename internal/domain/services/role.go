@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RoleService struct {
+	roleRepo  interfaces.RoleRepository
+	auditSink interfaces.AuditSink
+}
+
+func NewRoleService(roleRepo interfaces.RoleRepository, auditSink interfaces.AuditSink) *RoleService {
+	return &RoleService{roleRepo: roleRepo, auditSink: auditSink}
+}
+
+func (s *RoleService) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return s.roleRepo.FindRoles(ctx)
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, name, description string, permissions []string, userID uuid.UUID, ipAddress, requestID string) (*models.Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`).MatchString(name) {
+		return nil, fmt.Errorf("role name must be 2-20 lowercase letters, digits or underscores")
+	}
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.roleRepo.FindRole(ctx, name); err == nil {
+		return nil, fmt.Errorf("role already exists")
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+	}
+	err = s.roleRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.roleRepo.CreateRole(tx, role); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+		if err := s.roleRepo.SetPermissions(tx, name, permissions); err != nil {
+			return fmt.Errorf("failed to set permissions: %w", err)
+		}
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "role",
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Created role %s with permissions %s", name, strings.Join(permissions, ", ")),
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.FindRole(ctx, name)
+}
+
+func (s *RoleService) UpdateRolePermissions(ctx context.Context, name string, permissions []string, userID uuid.UUID, ipAddress, requestID string) (*models.Role, error) {
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.roleRepo.FindRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	old := make([]string, len(role.Permissions))
+	for i, p := range role.Permissions {
+		old[i] = p.Permission
+	}
+
+	err = s.roleRepo.WithTransaction(ctx, func(tx context.Context) error {
+		role.UpdatedBy = userID
+		if err := s.roleRepo.UpdateRole(tx, role); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if err := s.roleRepo.SetPermissions(tx, name, permissions); err != nil {
+			return fmt.Errorf("failed to set permissions: %w", err)
+		}
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "update",
+			TableName: "role",
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Changed permissions of role %s from [%s] to [%s]", name, strings.Join(old, ", "), strings.Join(permissions, ", ")),
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.FindRole(ctx, name)
+}
+
+func (s *RoleService) DeleteRole(ctx context.Context, name string, userID uuid.UUID, ipAddress, requestID string) error {
+	if _, ok := models.DefaultRolePermissions[name]; ok {
+		return fmt.Errorf("default roles cannot be deleted")
+	}
+	if _, err := s.roleRepo.FindRole(ctx, name); err != nil {
+		return err
+	}
+	count, err := s.roleRepo.CountUsersWithRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("role is assigned to %d users", count)
+	}
+
+	return s.roleRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.roleRepo.DeleteRole(tx, name); err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "delete",
+			TableName: "role",
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Deleted role %s", name),
+			CreatedAt: time.Now(),
+		})
+	})
+}
+
+// normalizePermissions rejects unknown permissions and returns the rest
+// sorted and de-duplicated.
+func normalizePermissions(permissions []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, p := range models.Permissions {
+		known[p] = true
+	}
+
+	seen := map[string]bool{}
+	result := []string{}
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !known[p] {
+			return nil, fmt.Errorf("unknown permission %q", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetSalary records a salary change taking effect on effectiveDate, which may
+// be in the past or the future. User.Salary is kept at the salary in effect
+// today. A user without salary history, created before it was kept, first
+// gets a change recording their stored salary from their hire date, so a
+// change only applies from its effective date.
+func (s *UserService) SetSalary(ctx context.Context, userIDStr string, amount float64, effectiveDate, reason string, adminID uuid.UUID, ipAddress, requestID string) (*models.SalaryChange, error) {
+	user, err := s.GetUser(ctx, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("salary must be greater than zero")
+	}
+	effective, err := time.Parse("2006-01-02", effectiveDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective date format: %w", err)
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 255 {
+		return nil, fmt.Errorf("reason must be at most 255 characters")
+	}
+
+	change := &models.SalaryChange{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		Amount:        amount,
+		EffectiveDate: effective,
+		Reason:        reason,
+		CreatedBy:     adminID,
+	}
+	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		history, err := s.userRepo.FindSalaryChanges(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if opening := openingSalaryChange(user, history, effective, adminID); opening != nil {
+			if err := s.userRepo.CreateSalaryChange(tx, opening); err != nil {
+				return fmt.Errorf("failed to record previous salary: %w", err)
+			}
+		}
+		if err := s.userRepo.CreateSalaryChange(tx, change); err != nil {
+			return fmt.Errorf("failed to record salary: %w", err)
+		}
+		changes, err := s.userRepo.FindSalaryChanges(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if current := currentSalary(changes, time.Now()); current != user.Salary {
+			user.Salary = current
+			user.UpdatedBy = adminID
+			if err := s.userRepo.Update(tx, user); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "set_salary",
+			TableName: "salary_change",
+			RecordID:  change.ID,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Set salary of user %s to %.2f effective %s", user.Username, amount, effectiveDate),
+			CreatedAt: time.Now(),
+		}
+		if reason != "" {
+			audit.Details += ": " + reason
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (s *UserService) ListSalaryChanges(ctx context.Context, userIDStr string) ([]*models.SalaryChange, error) {
+	user, err := s.GetUser(ctx, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.FindSalaryChanges(ctx, user.ID)
+}
+
+// currentSalary returns the salary in effect on date from a history sorted
+// oldest first. Before the first change, the first change applies.
+func currentSalary(changes []*models.SalaryChange, date time.Time) float64 {
+	if len(changes) == 0 {
+		return 0
+	}
+	salary := changes[0].Amount
+	for _, c := range changes {
+		if c.EffectiveDate.After(date) {
+			break
+		}
+		salary = c.Amount
+	}
+	return salary
+}
+
+// openingSalaryChange returns the change recording the stored salary of a user
+// without salary history, effective from their hire date or, without one, the
+// date they were created. It returns nil when there is history, no stored
+// salary, or the new change takes effect on or before that date.
+func openingSalaryChange(user *models.User, history []*models.SalaryChange, effective time.Time, adminID uuid.UUID) *models.SalaryChange {
+	if len(history) > 0 || user.Salary <= 0 {
+		return nil
+	}
+	start := user.CreatedAt
+	if user.HireDate != nil {
+		start = *user.HireDate
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	if !start.Before(effective) {
+		return nil
+	}
+	return &models.SalaryChange{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		Amount:        user.Salary,
+		EffectiveDate: start,
+		Reason:        "Salary before history was kept",
+		CreatedBy:     adminID,
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleService struct {
+	scheduleRepo   interfaces.ScheduleRepository
+	attendanceRepo interfaces.AttendanceRepository
+	auditSink      interfaces.AuditSink
+}
+
+func NewScheduleService(scheduleRepo interfaces.ScheduleRepository, attendanceRepo interfaces.AttendanceRepository, auditSink interfaces.AuditSink) *ScheduleService {
+	return &ScheduleService{scheduleRepo: scheduleRepo, attendanceRepo: attendanceRepo, auditSink: auditSink}
+}
+
+// defaultLeadDays is how far ahead a schedule creates periods when its lead
+// days are not given.
+const defaultLeadDays = 30
+
+// CreateSchedule creates a pay schedule. A nil leadDays uses defaultLeadDays.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, name, frequency string, dayOfMonth int, anchorDate string, leadDays *int, userID uuid.UUID, ipAddress, requestID string) (*models.PaySchedule, error) {
+	name = strings.TrimSpace(name)
+	frequency = strings.ToLower(frequency)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	lead := defaultLeadDays
+	if leadDays != nil {
+		lead = *leadDays
+	}
+	if lead < 0 || lead > 366 {
+		return nil, fmt.Errorf("lead days must be between 0 and 366")
+	}
+
+	schedule := &models.PaySchedule{
+		ID:        uuid.New(),
+		Name:      name,
+		Frequency: frequency,
+		LeadDays:  lead,
+		Active:    true,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	switch frequency {
+	case "monthly":
+		if dayOfMonth < 1 || dayOfMonth > 28 {
+			return nil, fmt.Errorf("day of month must be between 1 and 28")
+		}
+		schedule.DayOfMonth = dayOfMonth
+	case "semi_monthly":
+	case "weekly", "biweekly":
+		anchor, err := time.Parse("2006-01-02", anchorDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anchor date format: %w", err)
+		}
+		schedule.AnchorDate = anchor
+	default:
+		return nil, fmt.Errorf("frequency must be monthly, semi_monthly, biweekly or weekly")
+	}
+
+	if err := s.scheduleRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.scheduleRepo.CreateSchedule(tx, schedule); err != nil {
+			return fmt.Errorf("failed to create pay schedule: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "pay_schedule",
+			RecordID:  schedule.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Created %s pay schedule %s", frequency, name),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduleService) ListSchedules(ctx context.Context) ([]*models.PaySchedule, error) {
+	return s.scheduleRepo.FindSchedules(ctx, false)
+}
+
+func (s *ScheduleService) DeactivateSchedule(ctx context.Context, scheduleID string, userID uuid.UUID, ipAddress, requestID string) error {
+	parsedID, err := uuid.Parse(scheduleID)
+	if err != nil {
+		return fmt.Errorf("invalid pay schedule ID: %w", err)
+	}
+	schedule, err := s.scheduleRepo.FindScheduleByID(ctx, parsedID)
+	if err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.WithTransaction(ctx, func(tx context.Context) error {
+		schedule.Active = false
+		schedule.UpdatedBy = userID
+		if err := s.scheduleRepo.UpdateSchedule(tx, schedule); err != nil {
+			return fmt.Errorf("failed to deactivate pay schedule: %w", err)
+		}
+
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "deactivate",
+			TableName: "pay_schedule",
+			RecordID:  schedule.ID,
+			UserID:    userID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Deactivated pay schedule %s", schedule.Name),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
+
+// GeneratePeriods creates every period of the active schedules that is
+// current at asOf or starts within the schedule's lead days. Periods that
+// already exist with the same dates are skipped, so running it repeatedly
+// is safe. Periods that would overlap a different existing period are
+// skipped and logged.
+func (s *ScheduleService) GeneratePeriods(ctx context.Context, asOf time.Time, userID uuid.UUID, ipAddress, requestID string) ([]*models.AttendancePeriod, error) {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	var created []*models.AttendancePeriod
+
+	err := s.scheduleRepo.WithGenerationLock(ctx, func(tx context.Context) error {
+		schedules, err := s.scheduleRepo.FindSchedules(tx, true)
+		if err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			// The scheduler runs for every tenant, so scope each schedule's
+			// periods and overlap checks to the tenant owning it.
+			tx := tenant.WithID(tx, schedule.TenantID)
+			until := asOf.AddDate(0, 0, schedule.LeadDays)
+			for start, end := schedulePeriodContaining(schedule, asOf); !start.After(until); start, end = schedulePeriodContaining(schedule, end.AddDate(0, 0, 1)) {
+				overlapping, err := s.attendanceRepo.FindOverlappingPeriods(tx, start, end, uuid.Nil)
+				if err != nil {
+					return err
+				}
+				if len(overlapping) > 0 {
+					existing := overlapping[0]
+					if len(overlapping) > 1 || !sameDate(existing.StartDate, start) || !sameDate(existing.EndDate, end) {
+						log.Printf("Pay schedule %s: skipping period %s to %s, it overlaps period %s",
+							schedule.Name, start.Format("2006-01-02"), end.Format("2006-01-02"), existing.ID)
+					}
+					continue
+				}
+
+				period := &models.AttendancePeriod{
+					ID:        uuid.New(),
+					StartDate: start,
+					EndDate:   end,
+					CreatedBy: userID,
+					UpdatedBy: userID,
+				}
+				if err := s.attendanceRepo.CreatePeriod(tx, period); err != nil {
+					return fmt.Errorf("failed to create period: %w", err)
+				}
+
+				audit := &models.AuditLog{
+					ID:        uuid.New(),
+					Action:    "generate",
+					TableName: "attendance_period",
+					RecordID:  period.ID,
+					UserID:    userID,
+					IPAddress: ipAddress,
+					RequestID: requestID,
+					Details:   fmt.Sprintf("Generated attendance period %s from %s to %s using pay schedule %s", period.ID, start.Format("2006-01-02"), end.Format("2006-01-02"), schedule.Name),
+					CreatedAt: time.Now(),
+				}
+				if err := s.auditSink.Create(tx, audit); err != nil {
+					return fmt.Errorf("failed to log audit: %w", err)
+				}
+				created = append(created, period)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// RunGenerator calls GeneratePeriods every interval until ctx is cancelled.
+// Generated periods are attributed to the nil user ID.
+func (s *ScheduleService) RunGenerator(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		periods, err := s.GeneratePeriods(ctx, time.Now(), uuid.Nil, "", "")
+		if err != nil {
+			log.Printf("Period generator: %v", err)
+		} else if len(periods) > 0 {
+			log.Printf("Period generator: created %d attendance periods", len(periods))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// schedulePeriodContaining returns the start and end date of the period of
+// schedule that contains date.
+func schedulePeriodContaining(schedule *models.PaySchedule, date time.Time) (time.Time, time.Time) {
+	y, m, d := date.Date()
+	switch schedule.Frequency {
+	case "monthly":
+		start := time.Date(y, m, schedule.DayOfMonth, 0, 0, 0, 0, time.UTC)
+		if d < schedule.DayOfMonth {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start, start.AddDate(0, 1, -1)
+	case "semi_monthly":
+		if d <= 15 {
+			return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC), time.Date(y, m, 15, 0, 0, 0, 0, time.UTC)
+		}
+		return time.Date(y, m, 16, 0, 0, 0, 0, time.UTC), time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC)
+	default:
+		length := 7
+		if schedule.Frequency == "biweekly" {
+			length = 14
+		}
+		anchor := time.Date(schedule.AnchorDate.Year(), schedule.AnchorDate.Month(), schedule.AnchorDate.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(anchor).Hours() / 24)
+		offset := days % length
+		if offset < 0 {
+			offset += length
+		}
+		start := time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, length-1)
+	}
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
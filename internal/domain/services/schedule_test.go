@@ -0,0 +1,242 @@
+package services_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/database/dbtest"
+	"payslip/internal/infrastructure/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	tenantA = uuid.MustParse("0a000000-0000-0000-0000-00000000000a")
+	tenantB = uuid.MustParse("0b000000-0000-0000-0000-00000000000b")
+)
+
+type auditSinkStub struct {
+	entries []*models.AuditLog
+}
+
+func (s *auditSinkStub) Create(ctx context.Context, audit *models.AuditLog) error {
+	s.entries = append(s.entries, audit)
+	return nil
+}
+
+func (s *auditSinkStub) CreateBatch(ctx context.Context, audits []*models.AuditLog) error {
+	s.entries = append(s.entries, audits...)
+	return nil
+}
+
+func newScheduleService(t *testing.T) (*services.ScheduleService, *dbtest.Recorder) {
+	db, recorder := dbtest.Open(t)
+	service := services.NewScheduleService(repository.NewScheduleRepository(db), repository.NewAttendanceRepository(db), &auditSinkStub{})
+	return service, recorder
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+var periodColumns = []string{"id", "start_date", "end_date"}
+
+// generatedPeriods returns the start and end dates of the periods inserted.
+func generatedPeriods(t *testing.T, recorder *dbtest.Recorder) []string {
+	t.Helper()
+	var periods []string
+	for _, insert := range recorder.Statements(`INSERT INTO "attendance_periods"`) {
+		start, _ := insert.InsertValue("start_date")
+		end, _ := insert.InsertValue("end_date")
+		startDate, _ := start.(time.Time)
+		endDate, _ := end.(time.Time)
+		periods = append(periods, startDate.Format("2006-01-02")+".."+endDate.Format("2006-01-02"))
+	}
+	return periods
+}
+
+func TestGeneratePeriodsFollowsEachFrequency(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule []driver.Value // frequency, day_of_month, anchor_date, lead_days
+		want     []string
+	}{
+		{"monthly", []driver.Value{"monthly", int64(5), nil, int64(0)}, []string{"2025-06-05..2025-07-04"}},
+		{"monthly before its day", []driver.Value{"monthly", int64(25), nil, int64(0)}, []string{"2025-05-25..2025-06-24"}},
+		{"monthly with lead days", []driver.Value{"monthly", int64(1), nil, int64(30)}, []string{"2025-06-01..2025-06-30", "2025-07-01..2025-07-31"}},
+		{"semi-monthly", []driver.Value{"semi_monthly", int64(0), nil, int64(0)}, []string{"2025-06-16..2025-06-30"}},
+		{"weekly", []driver.Value{"weekly", int64(0), date("2025-06-02"), int64(0)}, []string{"2025-06-16..2025-06-22"}},
+		{"biweekly", []driver.Value{"biweekly", int64(0), date("2025-06-02"), int64(0)}, []string{"2025-06-16..2025-06-29"}},
+		{"biweekly anchored later", []driver.Value{"biweekly", int64(0), date("2025-07-14"), int64(0)}, []string{"2025-06-16..2025-06-29"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, recorder := newScheduleService(t)
+			row := append([]driver.Value{uuid.NewString(), "Payroll", true}, tt.schedule...)
+			recorder.Returns(`FROM "pay_schedules"`, []string{"id", "name", "active", "frequency", "day_of_month", "anchor_date", "lead_days"}, row)
+
+			periods, err := service.GeneratePeriods(tenant.System(context.Background()), time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC), uuid.Nil, "", "")
+			if err != nil {
+				t.Fatalf("GeneratePeriods: %v", err)
+			}
+			got := generatedPeriods(t, recorder)
+			if len(periods) != len(tt.want) || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("generated %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePeriodsSkipsExistingPeriods(t *testing.T) {
+	service, recorder := newScheduleService(t)
+	recorder.Returns(`FROM "pay_schedules"`, []string{"id", "name", "active", "frequency", "day_of_month", "lead_days"},
+		[]driver.Value{uuid.NewString(), "Monthly", true, "monthly", int64(1), int64(0)})
+	recorder.Returns(`FROM "attendance_periods"`, periodColumns,
+		[]driver.Value{uuid.NewString(), date("2025-06-01"), date("2025-06-30")})
+
+	periods, err := service.GeneratePeriods(tenant.System(context.Background()), date("2025-06-10"), uuid.Nil, "", "")
+	if err != nil {
+		t.Fatalf("GeneratePeriods: %v", err)
+	}
+	if len(periods) != 0 || len(generatedPeriods(t, recorder)) != 0 {
+		t.Errorf("generated %v for a period that already exists", generatedPeriods(t, recorder))
+	}
+}
+
+func TestGeneratePeriodsHoldsTheGenerationLock(t *testing.T) {
+	service, recorder := newScheduleService(t)
+
+	if _, err := service.GeneratePeriods(tenant.System(context.Background()), time.Now(), uuid.Nil, "", ""); err != nil {
+		t.Fatalf("GeneratePeriods: %v", err)
+	}
+
+	statements := recorder.Statements("")
+	if len(statements) == 0 || !strings.Contains(statements[0].SQL, "pg_advisory_xact_lock") {
+		t.Errorf("first statement = %v, want the period generation lock", statements)
+	}
+}
+
+func TestCreateScheduleRejectsInvalidSchedules(t *testing.T) {
+	tests := []struct {
+		name, frequency string
+		dayOfMonth      int
+		anchorDate      string
+	}{
+		{"unknown frequency", "daily", 1, ""},
+		{"monthly on day 29", "monthly", 29, ""},
+		{"weekly without anchor", "weekly", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, recorder := newScheduleService(t)
+			if _, err := service.CreateSchedule(context.Background(), "Payroll", tt.frequency, tt.dayOfMonth, tt.anchorDate, nil, uuid.New(), "", ""); err == nil {
+				t.Error("CreateSchedule accepted the schedule")
+			}
+			if inserts := recorder.Statements(`INSERT INTO "pay_schedules"`); len(inserts) != 0 {
+				t.Errorf("stored an invalid schedule: %v", inserts)
+			}
+		})
+	}
+}
+
+// monthlySchedule is a pay_schedules row generating one period, the month
+// containing the generation date.
+func monthlySchedule(tenantID uuid.UUID) []driver.Value {
+	return []driver.Value{uuid.NewString(), tenantID.String(), "Monthly", "monthly", int64(1), int64(0), true}
+}
+
+var scheduleColumns = []string{"id", "tenant_id", "name", "frequency", "day_of_month", "lead_days", "active"}
+
+// The generator runs without a request tenant and must create each
+// schedule's periods, and check them for overlaps, in the schedule's tenant.
+func TestGeneratePeriodsScopesEachScheduleToItsTenant(t *testing.T) {
+	service, recorder := newScheduleService(t)
+	recorder.Returns(`FROM "pay_schedules"`, scheduleColumns, monthlySchedule(tenantA), monthlySchedule(tenantB))
+
+	periods, err := service.GeneratePeriods(tenant.System(context.Background()), time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), uuid.Nil, "", "")
+	if err != nil {
+		t.Fatalf("GeneratePeriods: %v", err)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods, want 2", len(periods))
+	}
+
+	overlapChecks := recorder.Statements(`FROM "attendance_periods"`)
+	inserts := recorder.Statements(`INSERT INTO "attendance_periods"`)
+	if len(overlapChecks) != 2 || len(inserts) != 2 {
+		t.Fatalf("got %d overlap checks and %d inserts, want 2 of each", len(overlapChecks), len(inserts))
+	}
+	for i, id := range []uuid.UUID{tenantA, tenantB} {
+		check := overlapChecks[i]
+		if !strings.Contains(check.SQL, `"attendance_periods"."tenant_id" = `) || !check.HasArg(id.String()) {
+			t.Errorf("overlap check %d is not scoped to tenant %s: %s %v", i, id, check.SQL, check.Args)
+		}
+		if got, _ := inserts[i].InsertValue("tenant_id"); got != id.String() {
+			t.Errorf("period %d created in tenant %v, want %s", i, got, id)
+		}
+	}
+}
+
+func TestGeneratePeriodsForTenantOnlyReadsItsSchedules(t *testing.T) {
+	service, recorder := newScheduleService(t)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	if _, err := service.GeneratePeriods(ctx, time.Now(), uuid.New(), "", ""); err != nil {
+		t.Fatalf("GeneratePeriods: %v", err)
+	}
+
+	queries := recorder.Statements(`FROM "pay_schedules"`)
+	if len(queries) != 1 {
+		t.Fatalf("got %d schedule queries, want 1", len(queries))
+	}
+	if !strings.Contains(queries[0].SQL, `"pay_schedules"."tenant_id" = `) || !queries[0].HasArg(tenantA.String()) {
+		t.Errorf("schedule query is not scoped to tenant A: %s %v", queries[0].SQL, queries[0].Args)
+	}
+}
+
+func TestDeactivateScheduleOfAnotherTenantIsNotFound(t *testing.T) {
+	service, recorder := newScheduleService(t)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	if err := service.DeactivateSchedule(ctx, uuid.NewString(), uuid.New(), "", ""); err == nil {
+		t.Fatal("DeactivateSchedule found a schedule outside tenant A")
+	}
+
+	queries := recorder.Statements(`FROM "pay_schedules"`)
+	if len(queries) != 1 || !queries[0].HasArg(tenantA.String()) {
+		t.Errorf("schedule lookup is not scoped to tenant A: %v", queries)
+	}
+	if updates := recorder.Statements(`UPDATE "pay_schedules"`); len(updates) != 0 {
+		t.Errorf("sent %d updates for a schedule outside tenant A", len(updates))
+	}
+}
+
+func TestCreateScheduleKeepsZeroLeadDays(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), tenantA)
+	zero := 0
+	for _, tc := range []struct {
+		leadDays *int
+		want     int64
+	}{
+		{nil, 30},
+		{&zero, 0},
+	} {
+		service, recorder := newScheduleService(t)
+		if _, err := service.CreateSchedule(ctx, "Monthly", "monthly", 1, "", tc.leadDays, uuid.New(), "", ""); err != nil {
+			t.Fatalf("CreateSchedule: %v", err)
+		}
+		inserts := recorder.Statements(`INSERT INTO "pay_schedules"`)
+		if len(inserts) != 1 {
+			t.Fatalf("got %d inserts, want 1", len(inserts))
+		}
+		if got, ok := inserts[0].InsertValue("lead_days"); !ok || got != tc.want {
+			t.Errorf("lead_days = %v, want %d", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"payslip/internal/domain/tenant"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// roleMapping maps an identity provider group to a role.
+type roleMapping struct {
+	group string
+	role  string
+}
+
+// SSOService signs users in through an OpenID Connect provider with the
+// authorization code flow and PKCE. Users are linked by the provider's
+// subject and, when enabled, created on their first login. Their role
+// follows their provider groups on every login.
+type SSOService struct {
+	provider        interfaces.OIDCProvider
+	ssoRepo         interfaces.SSORepository
+	userRepo        interfaces.UserRepository
+	roleRepo        interfaces.RoleRepository
+	auditSink       interfaces.AuditSink
+	issuer          string
+	roleMappings    []roleMapping
+	defaultRole     string
+	jitProvisioning bool
+	tenantID        uuid.UUID
+	stateTTL        time.Duration
+}
+
+// NewSSOService parses roleMappings, a comma-separated list of group=role
+// pairs. Users in no mapped group get defaultRole, or are refused when it is
+// empty. SSO users belong to tenantID.
+func NewSSOService(provider interfaces.OIDCProvider, ssoRepo interfaces.SSORepository, userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, auditSink interfaces.AuditSink, issuer, roleMappings, defaultRole string, jitProvisioning bool, tenantID uuid.UUID, stateTTL time.Duration) (*SSOService, error) {
+	s := &SSOService{
+		provider:        provider,
+		ssoRepo:         ssoRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		auditSink:       auditSink,
+		issuer:          strings.TrimSuffix(issuer, "/"),
+		defaultRole:     strings.ToLower(strings.TrimSpace(defaultRole)),
+		jitProvisioning: jitProvisioning,
+		tenantID:        tenantID,
+		stateTTL:        stateTTL,
+	}
+	for _, pair := range strings.Split(roleMappings, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.ToLower(strings.TrimSpace(role))
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid role mapping %q, want group=role", pair)
+		}
+		s.roleMappings = append(s.roleMappings, roleMapping{group: group, role: role})
+	}
+	return s, nil
+}
+
+// BeginLogin stores a new login state and returns the provider URL to send
+// the browser to.
+func (s *SSOService) BeginLogin(ctx context.Context) (string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.ssoRepo.CreateOIDCLoginState(ctx, &models.OIDCLoginState{
+		StateHash:    secret.Hash(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to store login state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return s.provider.AuthCodeURL(ctx, state, base64.RawURLEncoding.EncodeToString(challenge[:]), nonce)
+}
+
+// CompleteLogin handles the provider callback and returns the signed-in
+// user. Successful and failed logins are written to the audit log.
+func (s *SSOService) CompleteLogin(ctx context.Context, state, code, ipAddress, requestID string) (*models.User, error) {
+	stored, err := s.ssoRepo.TakeOIDCLoginState(ctx, secret.Hash(state))
+	if err != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+	identity, err := s.provider.Exchange(ctx, code, stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Usernames are unique across tenants, so look for free ones unscoped.
+	unscoped := tenant.System(ctx)
+	ctx = tenant.WithID(ctx, s.tenantID)
+	role := s.mapRole(identity.Groups)
+
+	user, err := s.ssoRepo.FindUserByExternalSubject(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		if !s.jitProvisioning {
+			s.auditLogin(ctx, "login_failed", nil, identity, ipAddress, requestID, "no linked account")
+			return nil, fmt.Errorf("no account is linked to this identity")
+		}
+		if role == "" {
+			s.auditLogin(ctx, "login_failed", nil, identity, ipAddress, requestID, "no role mapped")
+			return nil, fmt.Errorf("no role is mapped to your groups")
+		}
+		user, err = s.provisionUser(ctx, unscoped, identity, role, ipAddress, requestID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !user.ActiveOn(time.Now()) {
+		s.auditLogin(ctx, "login_failed", user, identity, ipAddress, requestID, "account is "+user.Status)
+		return nil, fmt.Errorf("account is %s", user.Status)
+	}
+	if role == "" {
+		s.auditLogin(ctx, "login_failed", user, identity, ipAddress, requestID, "no role mapped")
+		return nil, fmt.Errorf("no role is mapped to your groups")
+	}
+	if role != user.Role {
+		if err := s.syncRole(ctx, user, role, ipAddress, requestID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.auditLogin(ctx, "login", user, identity, ipAddress, requestID, "")
+	return user, nil
+}
+
+// LinkUser links an existing user to a provider subject so they can sign in
+// through single sign-on. An empty subject removes the link.
+func (s *SSOService) LinkUser(ctx context.Context, userIDStr, subject string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subject = strings.TrimSpace(subject)
+	details := fmt.Sprintf("Unlinked user %s from single sign-on", user.Username)
+	user.ExternalIssuer, user.ExternalSubject = "", nil
+	if subject != "" {
+		user.ExternalIssuer, user.ExternalSubject = s.issuer, &subject
+		details = fmt.Sprintf("Linked user %s to single sign-on subject %s", user.Username, subject)
+	}
+	user.UpdatedBy = adminID
+
+	if err := s.ssoRepo.UpdateExternalIdentity(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to link user: %w", err)
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "update",
+		TableName: "user",
+		RecordID:  user.ID,
+		UserID:    adminID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return nil, fmt.Errorf("failed to log audit: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *SSOService) mapRole(groups []string) string {
+	for _, mapping := range s.roleMappings {
+		for _, group := range groups {
+			if group == mapping.group {
+				return mapping.role
+			}
+		}
+	}
+	return s.defaultRole
+}
+
+// provisionUser creates the user for a first single sign-on login. The
+// password is random and unknown, so the user can only sign in through the
+// provider. Employees start without a salary.
+func (s *SSOService) provisionUser(ctx, unscoped context.Context, identity *interfaces.OIDCIdentity, role, ipAddress, requestID string) (*models.User, error) {
+	if _, err := s.roleRepo.FindRole(ctx, role); err != nil {
+		return nil, fmt.Errorf("unknown role %s", role)
+	}
+	username, err := s.availableUsername(unscoped, identity)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	subject := identity.Subject
+	user := &models.User{
+		ID:              uuid.New(),
+		Username:        username,
+		Password:        string(hash),
+		Role:            role,
+		FullName:        identity.Name,
+		Email:           identity.Email,
+		Status:          models.UserStatusActive,
+		ExternalIssuer:  identity.Issuer,
+		ExternalSubject: &subject,
+	}
+
+	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.userRepo.Create(tx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "user",
+			RecordID:  user.ID,
+			UserID:    user.ID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Provisioned user %s with role %s from single sign-on subject %s", user.Username, user.Role, subject),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// availableUsername derives an alphanumeric username from the provider's
+// preferred username or email, adding a number when it is taken.
+func (s *SSOService) availableUsername(ctx context.Context, identity *interfaces.OIDCIdentity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = nonAlphanumeric.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	for i := 1; i < 100; i++ {
+		username := base
+		if i > 1 {
+			username = fmt.Sprintf("%s%d", base, i)
+		}
+		if _, err := s.userRepo.FindByUsername(ctx, username); err != nil {
+			return username, nil
+		}
+	}
+	return "", fmt.Errorf("no free username for %s", base)
+}
+
+func (s *SSOService) syncRole(ctx context.Context, user *models.User, role, ipAddress, requestID string) error {
+	if _, err := s.roleRepo.FindRole(ctx, role); err != nil {
+		return fmt.Errorf("unknown role %s", role)
+	}
+	previous := user.Role
+	user.Role = role
+	user.UpdatedBy = user.ID
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    "update",
+		TableName: "user",
+		RecordID:  user.ID,
+		UserID:    user.ID,
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Changed role of %s from %s to %s following single sign-on groups", user.Username, previous, role),
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		return fmt.Errorf("failed to log audit: %w", err)
+	}
+	return nil
+}
+
+// auditLogin records a single sign-on login outcome. Audit failures are not
+// returned, as for password logins.
+func (s *SSOService) auditLogin(ctx context.Context, action string, user *models.User, identity *interfaces.OIDCIdentity, ipAddress, requestID, reason string) {
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    action,
+		TableName: "user",
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Single sign-on login as subject %s", identity.Subject),
+		CreatedAt: time.Now(),
+	}
+	if user != nil {
+		audit.RecordID = user.ID
+		audit.UserID = user.ID
+	}
+	if reason != "" {
+		audit.Details += ": " + reason
+	}
+	if action == "login_failed" {
+		audit.Outcome = models.AuditOutcomeFailure
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		log.Printf("Failed to log audit for single sign-on login as %s: %v", identity.Subject, err)
+	}
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"payslip/internal/domain/services"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/auth/mockidp"
+	"payslip/internal/infrastructure/database/dbtest"
+	"payslip/internal/infrastructure/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	loginStateColumns = []string{"state_hash", "code_verifier", "nonce", "expires_at"}
+	ssoUserColumns    = []string{"id", "tenant_id", "username", "role", "status", "external_issuer", "external_subject"}
+)
+
+// ssoTest signs alice, in the employees group, in through a mock identity
+// provider served by httptest.
+type ssoTest struct {
+	service  *services.SSOService
+	recorder *dbtest.Recorder
+	audits   *auditSinkStub
+	issuer   string
+}
+
+func newSSOTest(t *testing.T, jitProvisioning bool) *ssoTest {
+	t.Helper()
+	var idp http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idp.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	provider, err := mockidp.New(server.URL, "payslip", []mockidp.User{
+		{Subject: "alice-subject", Username: "alice", Email: "alice@example.com", Name: "Alice", Groups: []string{"employees"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp = provider
+
+	db, recorder := dbtest.Open(t)
+	audits := &auditSinkStub{}
+	client := auth.NewOIDCClient(server.URL, "payslip", "", "http://localhost:8084/login/oidc/callback", "openid profile email groups", "groups")
+	service, err := services.NewSSOService(client, repository.NewSSORepository(db), repository.NewUserRepository(db), repository.NewRoleRepository(db), audits,
+		server.URL, "payroll-admins=admin,employees=employee", "", jitProvisioning, tenantA, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Returns(`FROM "roles"`, []string{"tenant_id", "name"}, []driver.Value{tenantA.String(), "employee"})
+	return &ssoTest{service: service, recorder: recorder, audits: audits, issuer: server.URL}
+}
+
+// authorize begins a login and follows it to the provider, returning the
+// state and code the provider sends back and the login state that was stored.
+func (s *ssoTest) authorize(t *testing.T) (state, code string, stored []driver.Value) {
+	t.Helper()
+	authURL, err := s.service.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	inserts := s.recorder.Statements(`INSERT INTO "o_id_c_login_states"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d login states stored, want 1", len(inserts))
+	}
+	for _, column := range loginStateColumns {
+		value, _ := inserts[0].InsertValue(column)
+		stored = append(stored, value)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize redirect: %v", err)
+	}
+	callback := location.Query()
+	if callback.Get("code") == "" {
+		t.Fatalf("provider did not return a code: %s", location)
+	}
+	return callback.Get("state"), callback.Get("code"), stored
+}
+
+func TestSSOFirstLoginCreatesLinkedUser(t *testing.T) {
+	s := newSSOTest(t, true)
+	state, code, stored := s.authorize(t)
+	if stored[0] != secret.Hash(state) {
+		t.Fatalf("stored state hash %v, want the hash of the returned state", stored[0])
+	}
+	s.recorder.Returns(`DELETE FROM "o_id_c_login_states"`, loginStateColumns, stored)
+
+	user, err := s.service.CompleteLogin(context.Background(), state, code, "203.0.113.1", "")
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.Username != "alice" || user.Role != "employee" || user.ExternalIssuer != s.issuer || user.ExternalSubject == nil || *user.ExternalSubject != "alice-subject" {
+		t.Errorf("user = %+v", user)
+	}
+
+	inserts := s.recorder.Statements(`INSERT INTO "users"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d users created, want 1", len(inserts))
+	}
+	for column, want := range map[string]driver.Value{"tenant_id": tenantA.String(), "external_issuer": s.issuer, "external_subject": "alice-subject"} {
+		if got, _ := inserts[0].InsertValue(column); got != want {
+			t.Errorf("created user %s = %v, want %v", column, got, want)
+		}
+	}
+	var actions []string
+	for _, audit := range s.audits.entries {
+		actions = append(actions, audit.Action)
+	}
+	if len(actions) != 2 || actions[0] != "create" || actions[1] != "login" {
+		t.Errorf("audit actions = %v, want [create login]", actions)
+	}
+}
+
+func TestSSOLoginFindsLinkedUser(t *testing.T) {
+	s := newSSOTest(t, false)
+	state, code, stored := s.authorize(t)
+	s.recorder.Returns(`DELETE FROM "o_id_c_login_states"`, loginStateColumns, stored)
+	linked := uuid.New()
+	s.recorder.Returns(`external_subject = `, ssoUserColumns,
+		[]driver.Value{linked.String(), tenantA.String(), "alice", "employee", models.UserStatusActive, s.issuer, "alice-subject"})
+
+	user, err := s.service.CompleteLogin(context.Background(), state, code, "203.0.113.1", "")
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.ID != linked {
+		t.Errorf("signed in as %s, want the linked user %s", user.ID, linked)
+	}
+
+	lookups := s.recorder.Statements(`external_subject = `)
+	if len(lookups) != 1 || !lookups[0].HasArg(s.issuer) || !lookups[0].HasArg("alice-subject") || !lookups[0].HasArg(tenantA.String()) {
+		t.Errorf("linked user lookup = %v, want the issuer and subject in tenant A", lookups)
+	}
+	if inserts := s.recorder.Statements(`INSERT INTO "users"`); len(inserts) != 0 {
+		t.Errorf("created %d users for a linked identity", len(inserts))
+	}
+}
+
+func TestSSOLoginWithoutLinkedUserFailsWithoutProvisioning(t *testing.T) {
+	s := newSSOTest(t, false)
+	state, code, stored := s.authorize(t)
+	s.recorder.Returns(`DELETE FROM "o_id_c_login_states"`, loginStateColumns, stored)
+
+	if _, err := s.service.CompleteLogin(context.Background(), state, code, "203.0.113.1", ""); err == nil {
+		t.Fatal("CompleteLogin signed in an identity linked to no user")
+	}
+	if inserts := s.recorder.Statements(`INSERT INTO "users"`); len(inserts) != 0 {
+		t.Errorf("created %d users with provisioning disabled", len(inserts))
+	}
+	if len(s.audits.entries) != 1 || s.audits.entries[0].Action != "login_failed" {
+		t.Errorf("audit entries = %+v, want one login_failed", s.audits.entries)
+	}
+}
+
+func TestSSOLoginRejectsBadStateOrNonce(t *testing.T) {
+	tests := []struct {
+		name   string
+		forged bool                        // the callback carries a state that was never issued
+		modify func(stored []driver.Value) // changes the stored login state
+	}{
+		{"unknown state", true, nil},
+		{"expired state", false, func(stored []driver.Value) { stored[3] = time.Now().Add(-time.Minute) }},
+		{"wrong nonce", false, func(stored []driver.Value) { stored[2] = "another-nonce" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSSOTest(t, true)
+			state, code, stored := s.authorize(t)
+			if tt.forged {
+				state = "forged-state"
+			} else {
+				tt.modify(stored)
+				s.recorder.Returns(`DELETE FROM "o_id_c_login_states"`, loginStateColumns, stored)
+			}
+
+			if user, err := s.service.CompleteLogin(context.Background(), state, code, "203.0.113.1", ""); err == nil {
+				t.Fatalf("CompleteLogin signed in %+v", user)
+			}
+			takes := s.recorder.Statements(`DELETE FROM "o_id_c_login_states"`)
+			if len(takes) != 1 || !takes[0].HasArg(secret.Hash(state)) {
+				t.Errorf("login state lookup = %v, want one by the callback's state hash", takes)
+			}
+			if queries := s.recorder.Statements(`"users"`); len(queries) != 0 {
+				t.Errorf("looked up users after a bad login: %v", queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantService creates the companies served by this deployment. It is only
+// exposed through payslipctl, so tenant admins cannot create tenants.
+type TenantService struct {
+	tenantRepo  interfaces.TenantRepository
+	userService *UserService
+}
+
+func NewTenantService(tenantRepo interfaces.TenantRepository, userService *UserService) *TenantService {
+	return &TenantService{tenantRepo: tenantRepo, userService: userService}
+}
+
+// CreateTenant creates a tenant together with its default roles and first
+// admin user.
+func (s *TenantService) CreateTenant(ctx context.Context, name, adminUsername, adminPassword string) (*models.Tenant, *models.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	if adminPassword == "" {
+		return nil, nil, fmt.Errorf("admin password is required")
+	}
+
+	t := &models.Tenant{ID: uuid.New(), Name: name}
+	ctx = tenant.WithID(ctx, t.ID)
+	var admin *models.User
+	err := s.tenantRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.tenantRepo.CreateTenant(tx, t); err != nil {
+			return fmt.Errorf("failed to create tenant: %w", err)
+		}
+		// Roles belong to their tenant, so the admin role must exist before
+		// the tenant's first admin is given it.
+		if err := s.userService.roleRepo.SeedDefaultRoles(tx); err != nil {
+			return err
+		}
+		var err error
+		admin, err = s.userService.newUser(tx, adminUsername, adminPassword, "admin", 0, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "create",
+			TableName: "tenant",
+			RecordID:  t.ID,
+			Details:   fmt.Sprintf("Created tenant %s", t.Name),
+			CreatedAt: time.Now(),
+		}
+		if err := s.userService.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return s.userService.createUser(tx, admin, uuid.Nil, "", "", "Created tenant admin")
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return t, admin, nil
+}
+
+func (s *TenantService) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	return s.tenantRepo.FindTenants(ctx)
+}
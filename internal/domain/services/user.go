@@ -2,49 +2,102 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"log"
 	"payslip/internal/domain/interfaces"
 	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"payslip/internal/domain/tenant"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/brianvoe/gofakeit/v7"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
 	userRepo  interfaces.UserRepository
-	auditRepo interfaces.AuditRepository
+	roleRepo  interfaces.RoleRepository
+	sessions  interfaces.SessionRevoker
+	auditSink interfaces.AuditSink
+	policy    *PasswordPolicy
+	notifier  interfaces.Notifier
+	resetTTL  time.Duration
+	guard     *LoginGuard
 }
 
-func NewUserService(userRepo interfaces.UserRepository, auditRepo interfaces.AuditRepository) *UserService {
-	return &UserService{userRepo: userRepo, auditRepo: auditRepo}
+func NewUserService(userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, sessions interfaces.SessionRevoker, auditSink interfaces.AuditSink, policy *PasswordPolicy, notifier interfaces.Notifier, resetTTL time.Duration, guard *LoginGuard) *UserService {
+	return &UserService{
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+		sessions:  sessions,
+		auditSink: auditSink,
+		policy:    policy,
+		notifier:  notifier,
+		resetTTL:  resetTTL,
+		guard:     guard,
+	}
 }
 
-func (s *UserService) Register(ctx context.Context, username, password, role, adminIDStr, ipAddress, requestID string) (*models.User, error) {
+// Register creates a user. salary is the monthly salary and starts the
+// user's salary history; it is required for employees.
+func (s *UserService) Register(ctx context.Context, username, password, role string, salary float64, adminIDStr, ipAddress, requestID string) (*models.User, error) {
+	if password == "" {
+		return nil, fmt.Errorf("username, password, and role are required")
+	}
+
+	// Parse admin ID
+	adminID, err := uuid.Parse(adminIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin ID")
+	}
+
+	user, err := s.newUser(ctx, username, password, role, salary, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createUser(ctx, user, adminID, ipAddress, requestID, "Registered"); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// newUser validates a registration and returns the user to create. An empty
+// password is replaced by a random one nobody knows, for users who set their
+// own through a password reset token.
+func (s *UserService) newUser(ctx context.Context, username, password, role string, salary float64, adminID uuid.UUID) (*models.User, error) {
 	// Validate input
 	username = strings.TrimSpace(username)
-	role = strings.ToLower(role)
-	if username == "" || password == "" || (role != "employee" && role != "admin") {
-		return nil, fmt.Errorf("username, password, and valid role (employee or admin) are required")
+	role = strings.ToLower(strings.TrimSpace(role))
+	if username == "" || role == "" {
+		return nil, fmt.Errorf("username, password, and role are required")
 	}
-	if len(password) < 6 {
-		return nil, fmt.Errorf("password must be at least 6 characters long")
+	if _, err := s.roleRepo.FindRole(ctx, role); err != nil {
+		return nil, fmt.Errorf("unknown role %s", role)
+	}
+	if password == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		password = base64.RawURLEncoding.EncodeToString(raw)
+	} else if err := s.policy.Validate(password); err != nil {
+		return nil, err
 	}
 	if !regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString(username) {
 		return nil, fmt.Errorf("username must be alphanumeric")
 	}
-
-	// Check for duplicate
-	if _, err := s.userRepo.FindByUsername(ctx, username); err == nil {
-		return nil, fmt.Errorf("username already exists")
+	if salary < 0 || (role == "employee" && salary == 0) {
+		return nil, fmt.Errorf("salary must be greater than zero")
 	}
 
-	// Parse admin ID
-	adminID, err := uuid.Parse(adminIDStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid admin ID")
+	// Usernames are unique across tenants, so check every tenant.
+	if _, err := s.userRepo.FindByUsername(tenant.System(ctx), username); err == nil {
+		return nil, interfaces.ErrUsernameTaken
 	}
 
 	// Hash password
@@ -53,24 +106,39 @@ func (s *UserService) Register(ctx context.Context, username, password, role, ad
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Create user
-	user := &models.User{
+	return &models.User{
 		ID:        uuid.New(),
 		Username:  username,
 		Password:  string(hash),
 		Role:      role,
+		Salary:    salary,
+		Status:    models.UserStatusActive,
 		CreatedBy: adminID,
 		UpdatedBy: adminID,
-	}
-	if role == "employee" {
-		user.Salary = gofakeit.Float64Range(2000, 10000)
-	}
+	}, nil
+}
 
-	// Save user and audit log with transaction
-	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+// createUser saves a user from newUser with its initial salary and audit
+// entry in one transaction.
+func (s *UserService) createUser(ctx context.Context, user *models.User, adminID uuid.UUID, ipAddress, requestID, verb string) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
 		if err := s.userRepo.Create(tx, user); err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
+		if user.Salary > 0 {
+			if err := s.userRepo.CreateSalaryChange(tx, &models.SalaryChange{
+				ID:            uuid.New(),
+				UserID:        user.ID,
+				Amount:        user.Salary,
+				EffectiveDate: today,
+				Reason:        "Initial salary",
+				CreatedBy:     adminID,
+			}); err != nil {
+				return fmt.Errorf("failed to record salary: %w", err)
+			}
+		}
 		audit := &models.AuditLog{
 			ID:        uuid.New(),
 			Action:    "create",
@@ -79,26 +147,218 @@ func (s *UserService) Register(ctx context.Context, username, password, role, ad
 			UserID:    adminID,
 			IPAddress: ipAddress,
 			RequestID: requestID,
-			Details:   fmt.Sprintf("Registered user %s with role %s", username, role),
+			Details:   fmt.Sprintf("%s user %s with role %s and salary %.2f", verb, user.Username, user.Role, user.Salary),
+			CreatedAt: time.Now(),
 		}
-		return s.auditRepo.Create(tx, audit)
+		return s.auditSink.Create(tx, audit)
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	return user, nil
 }
 
-func (s *UserService) Login(ctx context.Context, username, password string) (*models.User, string, error) {
+// Login checks the credentials. Attempts are throttled by the login guard,
+// and both successful and failed logins are written to the audit log.
+func (s *UserService) Login(ctx context.Context, username, password, ipAddress, requestID string) (*models.User, string, error) {
+	// The tenant is not known before the user is found.
+	ctx = tenant.System(ctx)
+	if err := s.guard.Check(ctx, username, ipAddress); err != nil {
+		s.auditLogin(ctx, "login_blocked", nil, username, ipAddress, requestID, err.Error())
+		return nil, "", err
+	}
+
 	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	}
 	if err != nil {
+		reason := "invalid credentials"
+		locked, guardErr := s.guard.RecordFailure(ctx, username, ipAddress)
+		if guardErr != nil {
+			return nil, "", guardErr
+		}
+		if locked {
+			reason = "invalid credentials, account locked"
+		}
+		s.auditLogin(ctx, "login_failed", user, username, ipAddress, requestID, reason)
 		return nil, "", fmt.Errorf("invalid credentials")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, "", fmt.Errorf("invalid credentials")
+	if err := s.guard.RecordSuccess(ctx, username, ipAddress); err != nil {
+		return nil, "", err
+	}
+	if !user.ActiveOn(time.Now()) {
+		s.auditLogin(ctx, "login_failed", user, username, ipAddress, requestID, "account is "+user.Status)
+		return nil, "", fmt.Errorf("account is %s", user.Status)
 	}
+	s.auditLogin(ctx, "login", user, username, ipAddress, requestID, "")
 
 	return user, "", nil // Token generation moved to auth package
 }
+
+// auditLogin records a login outcome. user is nil when the username is
+// unknown. Audit failures are not returned so they cannot be used to tell
+// outcomes apart.
+func (s *UserService) auditLogin(ctx context.Context, action string, user *models.User, username, ipAddress, requestID, reason string) {
+	audit := &models.AuditLog{
+		ID:        uuid.New(),
+		Action:    action,
+		TableName: "user",
+		IPAddress: ipAddress,
+		RequestID: requestID,
+		Details:   fmt.Sprintf("Login as %s", username),
+		CreatedAt: time.Now(),
+	}
+	if user != nil {
+		audit.RecordID = user.ID
+		audit.UserID = user.ID
+		ctx = tenant.WithID(ctx, user.TenantID)
+	}
+	if reason != "" {
+		audit.Details += ": " + reason
+	}
+	if action == "login_failed" {
+		audit.Outcome = models.AuditOutcomeFailure
+	}
+	if err := s.auditSink.Create(ctx, audit); err != nil {
+		log.Printf("Failed to log audit for login as %s: %v", username, err)
+	}
+}
+
+// ChangePassword sets a new password after checking the current one. Every
+// session of the user is revoked, so the user has to log in again.
+func (s *UserService) ChangePassword(ctx context.Context, oldPassword, newPassword string, userID uuid.UUID, ipAddress, requestID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+	if oldPassword == newPassword {
+		return fmt.Errorf("new password must differ from the current password")
+	}
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, newPassword, userID, ipAddress, requestID, "change_password", "Changed own password")
+}
+
+// RequestPasswordReset issues a one-time reset token for the user and sends it
+// through the notifier. Earlier unused tokens of the user stop working.
+func (s *UserService) RequestPasswordReset(ctx context.Context, userIDStr string, adminID uuid.UUID, ipAddress, requestID string) (time.Time, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid user ID: %w", err)
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	reset := &models.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: secret.Hash(token),
+		ExpiresAt: time.Now().Add(s.resetTTL),
+		CreatedBy: adminID,
+	}
+
+	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.userRepo.ExpireUserPasswordResetTokens(tx, user.ID); err != nil {
+			return fmt.Errorf("failed to expire reset tokens: %w", err)
+		}
+		if err := s.userRepo.CreatePasswordResetToken(tx, reset); err != nil {
+			return fmt.Errorf("failed to create reset token: %w", err)
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "request_password_reset",
+			TableName: "user",
+			RecordID:  user.ID,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Issued password reset token for user %s, valid until %s", user.Username, reset.ExpiresAt.Format(time.RFC3339)),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		// Sending last means a failed delivery rolls the token back.
+		body := fmt.Sprintf("Use this token to set a new password before %s:\n%s", reset.ExpiresAt.Format(time.RFC3339), token)
+		if err := s.notifier.Notify(tx, user, "Password reset", body); err != nil {
+			return fmt.Errorf("failed to send reset token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return reset.ExpiresAt, nil
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset.
+// The token works once, and every session of the user is revoked.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword, ipAddress, requestID string) error {
+	reset, err := s.userRepo.FindPasswordResetTokenByHash(ctx, secret.Hash(token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(tenant.System(ctx), reset.UserID)
+	if err != nil {
+		return err
+	}
+	ctx = tenant.WithID(ctx, user.TenantID)
+
+	return s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		used, err := s.userRepo.UsePasswordResetToken(tx, reset.ID)
+		if err != nil {
+			return fmt.Errorf("failed to use reset token: %w", err)
+		}
+		if !used {
+			return fmt.Errorf("invalid or expired reset token")
+		}
+		return s.setPassword(tx, user, newPassword, user.ID, ipAddress, requestID, "reset_password", "Reset password with a token")
+	})
+}
+
+// setPassword stores the new password hash, revokes every session of the user
+// and writes the audit entry.
+func (s *UserService) setPassword(ctx context.Context, user *models.User, password string, actorID uuid.UUID, ipAddress, requestID, action, details string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.userRepo.UpdatePassword(tx, user.ID, string(hash), actorID); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		if err := s.sessions.RevokeAllSessions(tx, user.ID, actorID, ipAddress, requestID); err != nil {
+			return err
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    action,
+			TableName: "user",
+			RecordID:  user.ID,
+			UserID:    actorID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("%s for user %s", details, user.Username),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"payslip/internal/domain/models"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type userImportRecord struct {
+	Username string  `json:"username"`
+	Role     string  `json:"role"`
+	Salary   float64 `json:"salary"`
+	Password string  `json:"password"`
+	line     int
+	err      error
+}
+
+type userImportRow struct {
+	result *models.ImportRowResult
+	user   *models.User
+	invite bool
+}
+
+// ImportUsers registers users from a CSV or JSON document, validating each
+// row like Register. format is "csv" or "json".
+//
+// CSV needs a header row with username, role and salary columns and may have
+// a password column. JSON is an array of objects with the same keys. Users
+// without a password get a password reset token through the notifier once
+// they are created.
+//
+// With atomic set, nothing is created when any row fails, and all users are
+// created in one transaction. Otherwise each valid row is created on its own
+// and failed rows are only reported. dryRun only validates.
+func (s *UserService) ImportUsers(ctx context.Context, r io.Reader, format string, dryRun, atomic bool, adminID uuid.UUID, ipAddress, requestID string) ([]*models.ImportRowResult, error) {
+	var records []*userImportRecord
+	var err error
+	switch strings.ToLower(format) {
+	case "csv":
+		records, err = readUserImportCSV(r)
+	case "json":
+		records, err = readUserImportJSON(r)
+	default:
+		return nil, fmt.Errorf("format must be csv or json")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("import contains no rows")
+	}
+
+	var rows []*userImportRow
+	var results []*models.ImportRowResult
+	seen := map[string]int{}
+	failed := 0
+	for _, record := range records {
+		result := &models.ImportRowResult{Row: record.line, Key: strings.TrimSpace(record.Username), Status: "valid"}
+		results = append(results, result)
+
+		err := record.err
+		var user *models.User
+		if err == nil {
+			key := strings.ToLower(result.Key)
+			if prev, ok := seen[key]; ok {
+				err = fmt.Errorf("duplicate of row %d", prev)
+			} else {
+				seen[key] = record.line
+				user, err = s.newUser(ctx, record.Username, record.Password, record.Role, record.Salary, adminID)
+			}
+		}
+		if err != nil {
+			result.Status, result.Error = "error", err.Error()
+			failed++
+			continue
+		}
+		rows = append(rows, &userImportRow{result: result, user: user, invite: record.Password == ""})
+	}
+
+	if atomic && failed > 0 {
+		return results, fmt.Errorf("%d of %d rows failed validation", failed, len(results))
+	}
+	if dryRun {
+		return results, nil
+	}
+
+	if atomic {
+		err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+			for _, row := range rows {
+				if err := s.createUser(tx, row.user, adminID, ipAddress, requestID, "Imported"); err != nil {
+					return fmt.Errorf("row %d: %w", row.result.Row, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return results, fmt.Errorf("failed to import users: %w", err)
+		}
+		for _, row := range rows {
+			row.result.Status, row.result.RecordID = "created", row.user.ID.String()
+		}
+	} else {
+		for _, row := range rows {
+			if err := s.createUser(ctx, row.user, adminID, ipAddress, requestID, "Imported"); err != nil {
+				row.result.Status, row.result.Error = "error", err.Error()
+				continue
+			}
+			row.result.Status, row.result.RecordID = "created", row.user.ID.String()
+		}
+	}
+
+	// Tokens are sent after the users are committed so that a rolled back
+	// import never sends working tokens.
+	for _, row := range rows {
+		if row.invite && row.result.Status == "created" {
+			if _, err := s.RequestPasswordReset(ctx, row.user.ID.String(), adminID, ipAddress, requestID); err != nil {
+				row.result.Error = fmt.Sprintf("user created, but the password setup token was not sent: %v", err)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func readUserImportCSV(r io.Reader) ([]*userImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	usernameCol, hasUsername := columns["username"]
+	roleCol, hasRole := columns["role"]
+	salaryCol, hasSalary := columns["salary"]
+	if !hasUsername || !hasRole || !hasSalary {
+		return nil, fmt.Errorf("CSV header must contain username, role and salary columns")
+	}
+	passwordCol, hasPassword := columns["password"]
+	if !hasPassword {
+		passwordCol = -1
+	}
+
+	var records []*userImportRecord
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		record := &userImportRecord{line: line, err: err}
+		records = append(records, record)
+		if err != nil {
+			continue
+		}
+
+		field := func(i int) string {
+			if i < 0 || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		record.Username = field(usernameCol)
+		record.Role = field(roleCol)
+		record.Password = field(passwordCol)
+		if value := field(salaryCol); value != "" {
+			record.Salary, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				record.err = fmt.Errorf("invalid salary %q", value)
+			}
+		}
+	}
+	return records, nil
+}
+
+func readUserImportJSON(r io.Reader) ([]*userImportRecord, error) {
+	var records []*userImportRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	for i, record := range records {
+		if record == nil {
+			records[i] = &userImportRecord{err: fmt.Errorf("row is null")}
+		}
+		records[i].line = i + 1
+	}
+	return records, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"payslip/internal/domain/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *UserService) GetUser(ctx context.Context, userIDStr string) (*models.User, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+// UpdateProfile replaces the user's personal details. An empty hireDate
+// clears the hire date.
+func (s *UserService) UpdateProfile(ctx context.Context, userIDStr, fullName, email, position, hireDate string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error) {
+	user, err := s.GetUser(ctx, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName = strings.TrimSpace(fullName)
+	email = strings.TrimSpace(email)
+	position = strings.TrimSpace(position)
+	if len(fullName) > 100 || len(email) > 100 || len(position) > 100 {
+		return nil, fmt.Errorf("full name, email and position must be at most 100 characters")
+	}
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return nil, fmt.Errorf("invalid email address")
+		}
+	}
+	var hire *time.Time
+	if hireDate != "" {
+		parsed, err := time.Parse("2006-01-02", hireDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hire date format: %w", err)
+		}
+		hire = &parsed
+	}
+
+	user.FullName = fullName
+	user.Email = email
+	user.Position = position
+	user.HireDate = hire
+	user.UpdatedBy = adminID
+
+	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.userRepo.Update(tx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "update",
+			TableName: "user",
+			RecordID:  user.ID,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Updated profile of user %s: full name %q, email %q, position %q, hire date %q", user.Username, fullName, email, position, hireDate),
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangeStatus deactivates, terminates or reactivates a user. Deactivation
+// and termination need an effective date, the first day the user can no
+// longer log in and is no longer paid. When that day has come, the user's
+// sessions are revoked straight away. Terminated users cannot be reactivated.
+func (s *UserService) ChangeStatus(ctx context.Context, userIDStr, status, effectiveDate, reason string, adminID uuid.UUID, ipAddress, requestID string) (*models.User, error) {
+	user, err := s.GetUser(ctx, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == adminID {
+		return nil, fmt.Errorf("you cannot change your own status")
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 255 {
+		return nil, fmt.Errorf("reason must be at most 255 characters")
+	}
+
+	oldStatus := user.Status
+	var effective *time.Time
+	switch status = strings.ToLower(status); status {
+	case models.UserStatusActive:
+		if user.Status == models.UserStatusTerminated {
+			return nil, fmt.Errorf("terminated users cannot be reactivated")
+		}
+		if user.Status == models.UserStatusActive {
+			return nil, fmt.Errorf("user is already active")
+		}
+	case models.UserStatusDeactivated, models.UserStatusTerminated:
+		if user.Status == models.UserStatusTerminated {
+			return nil, fmt.Errorf("user is already terminated")
+		}
+		parsed, err := time.Parse("2006-01-02", effectiveDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid effective date format: %w", err)
+		}
+		if user.HireDate != nil && parsed.Before(*user.HireDate) {
+			return nil, fmt.Errorf("effective date cannot be before the hire date")
+		}
+		effective = &parsed
+	default:
+		return nil, fmt.Errorf("status must be active, deactivated or terminated")
+	}
+
+	user.Status = status
+	user.StatusEffectiveDate = effective
+	user.StatusReason = reason
+	user.UpdatedBy = adminID
+
+	err = s.userRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.userRepo.Update(tx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if !user.ActiveOn(time.Now()) {
+			if err := s.sessions.RevokeAllSessions(tx, user.ID, adminID, ipAddress, requestID); err != nil {
+				return err
+			}
+		}
+		details := fmt.Sprintf("Changed status of user %s from %s to %s", user.Username, oldStatus, status)
+		if effective != nil {
+			details += " effective " + effectiveDate
+		}
+		if reason != "" {
+			details += ": " + reason
+		}
+		audit := &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "change_status",
+			TableName: "user",
+			RecordID:  user.ID,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   details,
+			CreatedAt: time.Now(),
+		}
+		if err := s.auditSink.Create(tx, audit); err != nil {
+			return fmt.Errorf("failed to log audit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,43 @@
+package services_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/services"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/database/dbtest"
+	"payslip/internal/infrastructure/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newUserService(t *testing.T) (*services.UserService, *dbtest.Recorder) {
+	db, recorder := dbtest.Open(t)
+	service := services.NewUserService(repository.NewUserRepository(db), repository.NewRoleRepository(db), nil, &auditSinkStub{},
+		&services.PasswordPolicy{MinLength: 8}, nil, time.Hour, nil)
+	recorder.Returns(`FROM "roles"`, []string{"tenant_id", "name"}, []driver.Value{tenantA.String(), "employee"})
+	return service, recorder
+}
+
+func TestRegisterRejectsUsernameTakenInAnotherTenant(t *testing.T) {
+	service, recorder := newUserService(t)
+	recorder.Returns(`FROM "users"`, []string{"id", "tenant_id", "username"}, []driver.Value{uuid.New().String(), tenantB.String(), "alice"})
+
+	_, err := service.Register(tenant.WithID(context.Background(), tenantA), "alice", "Str0ngPassw0rd", "employee", 5000, uuid.New().String(), "", "")
+	if !errors.Is(err, interfaces.ErrUsernameTaken) {
+		t.Fatalf("Register: err = %v, want ErrUsernameTaken", err)
+	}
+
+	lookups := recorder.Statements(`FROM "users"`)
+	if len(lookups) != 1 || strings.Contains(lookups[0].SQL, `"users"."tenant_id"`) {
+		t.Errorf("username lookup = %v, want one across tenants", lookups)
+	}
+	if inserts := recorder.Statements(`INSERT INTO "users"`); len(inserts) != 0 {
+		t.Errorf("created %d users with a taken username", len(inserts))
+	}
+}
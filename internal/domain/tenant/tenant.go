@@ -0,0 +1,46 @@
+// Package tenant carries the company a request acts for through its context.
+// Repositories only see rows of the tenant in the context, see
+// database.RegisterTenantScope.
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoTenant is returned for statements on tenant-owned tables whose context
+// is neither scoped to a tenant nor a system context.
+var ErrNoTenant = errors.New("no tenant in context")
+
+type contextKey struct{}
+
+type systemContextKey struct{}
+
+// WithID returns a context scoped to the tenant id.
+func WithID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to. ok is false for system
+// contexts and contexts that were never scoped.
+func FromContext(ctx context.Context) (id uuid.UUID, ok bool) {
+	id, ok = ctx.Value(contextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// System returns a context for work that spans tenants, such as migrations,
+// background jobs and the steps of a login before the user is known. It
+// drops the tenant ctx is scoped to, and its statements see the rows of every
+// tenant until it is scoped again with WithID.
+func System(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, nil)
+	return context.WithValue(ctx, systemContextKey{}, true)
+}
+
+// IsSystem reports whether ctx comes from System.
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemContextKey{}).(bool)
+	return system
+}
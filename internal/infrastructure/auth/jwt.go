@@ -2,7 +2,14 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/secret"
+	"payslip/internal/domain/tenant"
 	"strings"
 	"time"
 
@@ -10,63 +17,469 @@ import (
 	"github.com/google/uuid"
 )
 
+type Claims struct {
+	UserID      uuid.UUID
+	TenantID    uuid.UUID
+	Role        string
+	Permissions []string
+	TokenID     uuid.UUID
+	APIKeyID    *uuid.UUID // set when authenticated with an API key rather than a token
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
 type AuthService interface {
-	GenerateToken(userID uuid.UUID, role string) (string, error)
-	ValidateToken(tokenString string) (uuid.UUID, string, error)
+	GenerateToken(userID, tenantID uuid.UUID, role string, permissions []string) (string, error)
+	ValidateToken(ctx context.Context, tokenString string) (*Claims, error)
+	ValidateAPIKey(ctx context.Context, key, ipAddress string) (*Claims, error)
+	IssueTokens(ctx context.Context, user *models.User, ipAddress string) (*TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken, ipAddress string) (*TokenPair, error)
+	Logout(ctx context.Context, claims *Claims, refreshToken, ipAddress, requestID string) error
+	RevokeToken(ctx context.Context, jti, userID, adminID uuid.UUID, ipAddress, requestID string) error
+	RevokeAllSessions(ctx context.Context, userID, adminID uuid.UUID, ipAddress, requestID string) error
+	IssueMFAChallenge(userID uuid.UUID) (string, error)
+	ValidateMFAChallenge(tokenString string) (uuid.UUID, error)
+	JWKS() map[string]interface{}
 }
 
 type JWTService struct {
-	secret []byte
+	keys       *KeySet
+	tokenRepo  interfaces.TokenRepository
+	userRepo   interfaces.UserRepository
+	roleRepo   interfaces.RoleRepository
+	auditSink  interfaces.AuditSink
+	apiKeyRepo interfaces.APIKeyRepository
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	mfaTTL     time.Duration
 }
 
-func NewJWTService(secret string) *JWTService {
-	return &JWTService{secret: []byte(secret)}
+func NewJWTService(keys *KeySet, tokenRepo interfaces.TokenRepository, userRepo interfaces.UserRepository, roleRepo interfaces.RoleRepository, auditSink interfaces.AuditSink, apiKeyRepo interfaces.APIKeyRepository, accessTTL, refreshTTL, mfaTTL time.Duration) *JWTService {
+	return &JWTService{
+		keys:       keys,
+		tokenRepo:  tokenRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		auditSink:  auditSink,
+		apiKeyRepo: apiKeyRepo,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		mfaTTL:     mfaTTL,
+	}
 }
 
-func (s *JWTService) GenerateToken(userID uuid.UUID, role string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),
-		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+func (s *JWTService) GenerateToken(userID, tenantID uuid.UUID, role string, permissions []string) (string, error) {
+	now := time.Now()
+	key := s.keys.Active()
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"user_id":     userID.String(),
+		"tenant_id":   tenantID.String(),
+		"role":        role,
+		"permissions": permissions,
+		"jti":         uuid.New().String(),
+		"iat":         now.Unix(),
+		"exp":         now.Add(s.accessTTL).Unix(),
 	})
-	return token.SignedString(s.secret)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
 }
 
-func (s *JWTService) ValidateToken(tokenString string) (uuid.UUID, string, error) {
+func (s *JWTService) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
 	if tokenString == "" {
-		return uuid.UUID{}, "", fmt.Errorf("authorization header required")
+		return nil, fmt.Errorf("authorization header required")
 	}
 	if strings.HasPrefix(tokenString, "Bearer ") {
 		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 	}
 
+	mapClaims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// MFA challenge tokens only complete a login, they grant no access.
+	if _, ok := mapClaims["token_use"]; ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	userIDStr, ok := mapClaims["user_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	tenantIDStr, ok := mapClaims["tenant_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid tenant ID")
+	}
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant ID")
+	}
+	role, ok := mapClaims["role"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid role")
+	}
+	rawPermissions, _ := mapClaims["permissions"].([]interface{})
+	permissions := make([]string, 0, len(rawPermissions))
+	for _, p := range rawPermissions {
+		if permission, ok := p.(string); ok {
+			permissions = append(permissions, permission)
+		}
+	}
+	jtiStr, ok := mapClaims["jti"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid token ID")
+	}
+	jti, err := uuid.Parse(jtiStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token ID")
+	}
+	issuedAt, err := mapClaims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, fmt.Errorf("invalid token issue time")
+	}
+	expiresAt, err := mapClaims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, fmt.Errorf("invalid token expiry")
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(tenant.WithID(ctx, tenantID), jti, userID, issuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token has been revoked")
+	}
+
+	return &Claims{
+		UserID:      userID,
+		TenantID:    tenantID,
+		Role:        role,
+		Permissions: permissions,
+		TokenID:     jti,
+		IssuedAt:    issuedAt.Time,
+		ExpiresAt:   expiresAt.Time,
+	}, nil
+}
+
+// apiKeyTouchInterval bounds how often a key's last use is written.
+const apiKeyTouchInterval = time.Minute
+
+// ValidateAPIKey authenticates a request made with an API key. The claims
+// carry the key's tenant and the permissions in its scopes that the key's
+// creator still holds, so a key never outlives its creator's access.
+func (s *JWTService) ValidateAPIKey(ctx context.Context, key, ipAddress string) (*Claims, error) {
+	if !strings.HasPrefix(key, models.APIKeyPrefix) {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	apiKey, err := s.apiKeyRepo.FindAPIKeyByHash(tenant.System(ctx), secret.Hash(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return nil, fmt.Errorf("API key has been revoked")
+	}
+	if !now.Before(apiKey.ExpiresAt) {
+		return nil, fmt.Errorf("API key has expired")
+	}
+
+	ctx = tenant.WithID(ctx, apiKey.TenantID)
+	owner, err := s.userRepo.FindByID(ctx, apiKey.CreatedBy)
+	if err != nil || !owner.ActiveOn(now) {
+		return nil, fmt.Errorf("API key owner is no longer active")
+	}
+	held, err := s.roleRepo.FindPermissionsByRole(ctx, owner.Role)
+	if err != nil {
+		return nil, err
+	}
+	var permissions []string
+	for _, scope := range strings.Split(apiKey.Scopes, ",") {
+		for _, p := range held {
+			if p == scope {
+				permissions = append(permissions, scope)
+				break
+			}
+		}
+	}
+
+	if err := s.apiKeyRepo.TouchAPIKey(ctx, apiKey.ID, now, ipAddress, apiKeyTouchInterval); err != nil {
+		return nil, fmt.Errorf("failed to record API key use: %w", err)
+	}
+
+	return &Claims{
+		UserID:      owner.ID,
+		TenantID:    apiKey.TenantID,
+		Role:        owner.Role,
+		Permissions: permissions,
+		APIKeyID:    &apiKey.ID,
+		IssuedAt:    apiKey.CreatedAt,
+		ExpiresAt:   apiKey.ExpiresAt,
+	}, nil
+}
+
+// IssueMFAChallenge returns a short-lived token proving the user passed the
+// password step of a login. It is exchanged for a token pair once the second
+// factor is verified.
+func (s *JWTService) IssueMFAChallenge(userID uuid.UUID) (string, error) {
+	now := time.Now()
+	key := s.keys.Active()
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"user_id":   userID.String(),
+		"token_use": "mfa",
+		"jti":       uuid.New().String(),
+		"iat":       now.Unix(),
+		"exp":       now.Add(s.mfaTTL).Unix(),
+	})
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// ValidateMFAChallenge returns the user a challenge from IssueMFAChallenge
+// was issued to.
+func (s *JWTService) ValidateMFAChallenge(tokenString string) (uuid.UUID, error) {
+	mapClaims, err := s.parse(tokenString)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid or expired MFA token")
+	}
+	if use, _ := mapClaims["token_use"].(string); use != "mfa" {
+		return uuid.Nil, fmt.Errorf("invalid or expired MFA token")
+	}
+	userIDStr, _ := mapClaims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid or expired MFA token")
+	}
+	return userID, nil
+}
+
+// parse verifies the signature and expiry of a token signed by one of the
+// keys in the key set.
+func (s *JWTService) parse(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %v", token.Header["kid"])
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.secret, nil
+		return key.PublicKey, nil
 	})
 	if err != nil || !token.Valid {
-		return uuid.UUID{}, "", fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return uuid.UUID{}, "", fmt.Errorf("invalid token claims")
+		return nil, fmt.Errorf("invalid token claims")
 	}
+	return mapClaims, nil
+}
 
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return uuid.UUID{}, "", fmt.Errorf("invalid user ID")
+// IssueTokens returns a new access token carrying the user's tenant and the
+// permissions currently granted to their role, and starts a new refresh token family for the user.
+func (s *JWTService) IssueTokens(ctx context.Context, user *models.User, ipAddress string) (*TokenPair, error) {
+	return s.issueTokens(tenant.WithID(ctx, user.TenantID), user, uuid.New(), ipAddress)
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair. The presented
+// token is revoked; presenting an already revoked token is treated as theft
+// and revokes every token rotated from the same login.
+func (s *JWTService) RefreshTokens(ctx context.Context, refreshToken, ipAddress string) (*TokenPair, error) {
+	stored, err := s.tokenRepo.FindRefreshTokenByHash(ctx, secret.Hash(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
 	}
-	userID, err := uuid.Parse(userIDStr)
+	if stored.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("refresh token has been revoked")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("refresh token has expired")
+	}
+
+	user, err := s.userRepo.FindByID(tenant.System(ctx), stored.UserID)
 	if err != nil {
-		return uuid.UUID{}, "", fmt.Errorf("invalid user ID")
+		return nil, fmt.Errorf("invalid refresh token")
 	}
-	role, ok := claims["role"].(string)
-	if !ok {
-		return uuid.UUID{}, "", fmt.Errorf("invalid role")
+	if !user.ActiveOn(time.Now()) {
+		return nil, fmt.Errorf("account is %s", user.Status)
+	}
+	ctx = tenant.WithID(ctx, user.TenantID)
+
+	var pair *TokenPair
+	err = s.tokenRepo.WithTransaction(ctx, func(tx context.Context) error {
+		revoked, err := s.tokenRepo.RevokeRefreshToken(tx, stored.ID)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return fmt.Errorf("refresh token has been revoked")
+		}
+		pair, err = s.issueTokens(tx, user, stored.FamilyID, ipAddress)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout revokes the access token described by claims and, when given, the
+// refresh token issued with it.
+func (s *JWTService) Logout(ctx context.Context, claims *Claims, refreshToken, ipAddress, requestID string) error {
+	if claims.APIKeyID != nil {
+		return fmt.Errorf("API keys cannot log out, revoke the key instead")
+	}
+	return s.tokenRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.tokenRepo.RevokeAccessToken(tx, &models.RevokedToken{
+			JTI:       claims.TokenID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt,
+			CreatedBy: claims.UserID,
+		}); err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+
+		if refreshToken != "" {
+			stored, err := s.tokenRepo.FindRefreshTokenByHash(tx, secret.Hash(refreshToken))
+			if err != nil || stored.UserID != claims.UserID {
+				return fmt.Errorf("invalid refresh token")
+			}
+			if err := s.tokenRepo.RevokeRefreshTokenFamily(tx, stored.FamilyID); err != nil {
+				return fmt.Errorf("failed to revoke refresh token: %w", err)
+			}
+		}
+
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "logout",
+			TableName: "user",
+			RecordID:  claims.UserID,
+			UserID:    claims.UserID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("User %s logged out, revoked token %s", claims.UserID, claims.TokenID),
+			CreatedAt: time.Now(),
+		})
+	})
+}
+
+// RevokeToken adds the access token jti of the user to the denylist until it
+// expires. The user must belong to the tenant in ctx, and the entry only
+// denies that user's token.
+func (s *JWTService) RevokeToken(ctx context.Context, jti, userID, adminID uuid.UUID, ipAddress, requestID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.tokenRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.tokenRepo.RevokeAccessToken(tx, &models.RevokedToken{
+			JTI:       jti,
+			TenantID:  user.TenantID,
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(s.accessTTL),
+			CreatedBy: adminID,
+		}); err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "revoke",
+			TableName: "revoked_token",
+			RecordID:  jti,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Revoked access token %s of user %s", jti, user.Username),
+			CreatedAt: time.Now(),
+		})
+	})
+}
+
+// RevokeAllSessions invalidates every access and refresh token issued to the
+// user so far.
+func (s *JWTService) RevokeAllSessions(ctx context.Context, userID, adminID uuid.UUID, ipAddress, requestID string) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.WithTransaction(ctx, func(tx context.Context) error {
+		if err := s.tokenRepo.RevokeUserRefreshTokens(tx, userID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		// Token issue times have second precision, so round up to cover
+		// tokens issued earlier in the current second.
+		if err := s.tokenRepo.RevokeUserSessions(tx, &models.SessionRevocation{
+			UserID:        userID,
+			RevokedBefore: time.Now().Truncate(time.Second).Add(time.Second),
+			UpdatedBy:     adminID,
+		}); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		return s.auditSink.Create(tx, &models.AuditLog{
+			ID:        uuid.New(),
+			Action:    "revoke_sessions",
+			TableName: "user",
+			RecordID:  userID,
+			UserID:    adminID,
+			IPAddress: ipAddress,
+			RequestID: requestID,
+			Details:   fmt.Sprintf("Revoked all sessions for user %s", userID),
+			CreatedAt: time.Now(),
+		})
+	})
+}
+
+func (s *JWTService) JWKS() map[string]interface{} {
+	return s.keys.JWKS()
+}
+
+func (s *JWTService) issueTokens(ctx context.Context, user *models.User, familyID uuid.UUID, ipAddress string) (*TokenPair, error) {
+	permissions, err := s.roleRepo.FindPermissionsByRole(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.GenerateToken(user.ID, user.TenantID, user.Role, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := s.tokenRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: secret.Hash(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+		IPAddress: ipAddress,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
 	}
 
-	return userID, role, nil
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
 }
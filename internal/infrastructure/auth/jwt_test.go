@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/auth"
+	"payslip/internal/infrastructure/database/dbtest"
+	"payslip/internal/infrastructure/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	tenantA = uuid.MustParse("0a000000-0000-0000-0000-00000000000a")
+	tenantB = uuid.MustParse("0b000000-0000-0000-0000-00000000000b")
+)
+
+type auditSinkStub struct {
+	entries []*models.AuditLog
+}
+
+func (s *auditSinkStub) Create(ctx context.Context, audit *models.AuditLog) error {
+	s.entries = append(s.entries, audit)
+	return nil
+}
+
+func (s *auditSinkStub) CreateBatch(ctx context.Context, audits []*models.AuditLog) error {
+	s.entries = append(s.entries, audits...)
+	return nil
+}
+
+func newJWTService(t *testing.T) (*auth.JWTService, *dbtest.Recorder, *auditSinkStub) {
+	t.Helper()
+	keys, err := auth.NewHMACKeySet("test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, recorder := dbtest.Open(t)
+	audits := &auditSinkStub{}
+	service := auth.NewJWTService(keys, repository.NewTokenRepository(db), repository.NewUserRepository(db), repository.NewRoleRepository(db), audits,
+		repository.NewAPIKeyRepository(db), 15*time.Minute, time.Hour, 5*time.Minute)
+	return service, recorder, audits
+}
+
+func TestRevokeTokenStoresItsOwner(t *testing.T) {
+	service, recorder, audits := newJWTService(t)
+	owner, jti := uuid.New(), uuid.New()
+	recorder.Returns(`FROM "users"`, []string{"id", "tenant_id", "username"}, []driver.Value{owner.String(), tenantA.String(), "alice"})
+
+	if err := service.RevokeToken(tenant.WithID(context.Background(), tenantA), jti, owner, uuid.New(), "", ""); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+
+	inserts := recorder.Statements(`INSERT INTO "revoked_tokens"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d revoked tokens, want 1", len(inserts))
+	}
+	for column, want := range map[string]driver.Value{"jti": jti.String(), "user_id": owner.String(), "tenant_id": tenantA.String()} {
+		if got, _ := inserts[0].InsertValue(column); got != want {
+			t.Errorf("revoked token %s = %v, want %v", column, got, want)
+		}
+	}
+	if len(audits.entries) != 1 || audits.entries[0].Action != "revoke" {
+		t.Errorf("audit entries = %+v, want one revoke", audits.entries)
+	}
+}
+
+func TestRevokeTokenRejectsUserOfAnotherTenant(t *testing.T) {
+	service, recorder, audits := newJWTService(t)
+
+	// The owner is in tenant B, so the lookup scoped to tenant A finds nothing.
+	if err := service.RevokeToken(tenant.WithID(context.Background(), tenantA), uuid.New(), uuid.New(), uuid.New(), "", ""); err == nil {
+		t.Fatal("RevokeToken revoked a token of a user outside the tenant")
+	}
+
+	lookups := recorder.Statements(`FROM "users"`)
+	if len(lookups) != 1 || !lookups[0].HasArg(tenantA.String()) || lookups[0].HasArg(tenantB.String()) {
+		t.Errorf("owner lookup = %v, want one scoped to tenant A", lookups)
+	}
+	if inserts := recorder.Statements(`INSERT INTO "revoked_tokens"`); len(inserts) != 0 {
+		t.Errorf("stored %d revoked tokens", len(inserts))
+	}
+	if len(audits.entries) != 0 {
+		t.Errorf("audit entries = %+v, want none", audits.entries)
+	}
+}
+
+func TestValidateTokenChecksDenylistForTheTokensOwner(t *testing.T) {
+	service, recorder, _ := newJWTService(t)
+	owner := uuid.New()
+	token, err := service.GenerateToken(owner, tenantA, "employee", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := service.ValidateToken(context.Background(), "Bearer "+token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+
+	checks := recorder.Statements(`FROM "revoked_tokens"`)
+	if len(checks) != 1 || !checks[0].HasArg(claims.TokenID.String()) || !checks[0].HasArg(owner.String()) || !checks[0].HasArg(tenantA.String()) {
+		t.Errorf("denylist check = %v, want the jti of the owner in tenant A", checks)
+	}
+}
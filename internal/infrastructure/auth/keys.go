@@ -0,0 +1,202 @@
+// internal/infrastructure/auth/keys.go
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key identified by the kid JWT header. PrivateKey is nil for
+// retired keys that are kept only to verify tokens issued before a rotation.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+// KeySet holds the key used to sign new tokens and every key accepted when
+// verifying them.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet reads every *.pem file in dir. The file name without extension
+// is the key ID. Files may contain an RSA or Ed25519 private key, or only a
+// public key for verification. activeID selects the signing key and may be
+// empty when the directory holds exactly one private key.
+func LoadKeySet(dir, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	set := &KeySet{keys: map[string]*SigningKey{}}
+	var signers []*SigningKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		set.keys[key.ID] = key
+		if key.PrivateKey != nil {
+			signers = append(signers, key)
+		}
+	}
+
+	switch {
+	case activeID != "":
+		key, ok := set.keys[activeID]
+		if !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("no private key with ID %q in %s", activeID, dir)
+		}
+		set.active = key
+	case len(signers) == 1:
+		set.active = signers[0]
+	case len(signers) == 0:
+		return nil, fmt.Errorf("no private keys found in %s", dir)
+	default:
+		return nil, fmt.Errorf("%s holds several private keys, set the active key ID", dir)
+	}
+
+	return set, nil
+}
+
+// NewHMACKeySet signs and verifies with a shared secret. It is meant for local
+// development; the secret is never published through JWKS.
+func NewHMACKeySet(secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("JWT secret is empty")
+	}
+	key := &SigningKey{ID: "hs256", Method: jwt.SigningMethodHS256, PrivateKey: []byte(secret), PublicKey: []byte(secret)}
+	return &KeySet{active: key, keys: map[string]*SigningKey{key.ID: key}}, nil
+}
+
+func (k *KeySet) Active() *SigningKey {
+	return k.active
+}
+
+func (k *KeySet) Lookup(id string) (*SigningKey, bool) {
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// JWKS returns the public keys in JSON Web Key Set format.
+func (k *KeySet) JWKS() map[string]interface{} {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	keys := []map[string]string{}
+	for _, id := range ids {
+		switch pub := k.keys[id].PublicKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": id,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"kid": id,
+				"use": "sig",
+				"alg": "EdDSA",
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return map[string]interface{}{"keys": keys}
+}
+
+// EncodePrivateKey returns key as a PKCS #8 PEM block suitable for LoadKeySet.
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func parseKey(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, PrivateKey: key, PublicKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, PublicKey: key}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: key, PublicKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, PublicKey: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// LoadAuditKey reads the Ed25519 key audit entries are signed with. The file
+// may hold only the public key, which verifies signatures but cannot sign.
+// An empty path returns no key.
+func LoadAuditKey(path string) (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	if path == "" {
+		return nil, nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := parseKey("audit", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	public, ok := key.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: audit signing key must be Ed25519", path)
+	}
+	private, _ := key.PrivateKey.(ed25519.PrivateKey)
+	return private, public, nil
+}
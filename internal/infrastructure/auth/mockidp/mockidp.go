@@ -0,0 +1,286 @@
+// Package mockidp is a minimal OpenID Connect provider for local development
+// and for exercising single sign-on without a real identity provider. It
+// supports discovery, the authorization code flow with S256 PKCE, and JWKS.
+// Logins are not authenticated: the user is picked from a list.
+package mockidp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mockidp"
+
+// User is an identity the provider can sign in.
+type User struct {
+	Subject  string
+	Username string
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+type grant struct {
+	user          User
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	expiresAt     time.Time
+}
+
+// Provider serves the provider endpoints below its issuer URL.
+type Provider struct {
+	issuer   string
+	clientID string
+	users    []User
+	key      ed25519.PrivateKey
+	tokenTTL time.Duration
+
+	mu     sync.Mutex
+	grants map[string]*grant
+}
+
+// New returns a provider for issuer, such as "http://localhost:9000", that
+// only accepts clientID. A new signing key is generated on every start.
+func New(issuer, clientID string, users []User) (*Provider, error) {
+	if len(users) == 0 {
+		return nil, fmt.Errorf("at least one user is required")
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		issuer:   strings.TrimSuffix(issuer, "/"),
+		clientID: clientID,
+		users:    users,
+		key:      key,
+		tokenTTL: 5 * time.Minute,
+		grants:   map[string]*grant{},
+	}, nil
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.discovery(w)
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	case "/jwks":
+		p.jwks(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Provider) discovery(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"EdDSA"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "profile", "email", "groups"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "EdDSA",
+			"x":   base64.RawURLEncoding.EncodeToString(p.key.Public().(ed25519.PublicKey)),
+		}},
+	})
+}
+
+var pickUser = template.Must(template.New("pick").Parse(`<!DOCTYPE html>
+<title>Mock identity provider</title>
+<h1>Sign in as</h1>
+<ul>{{range .}}<li><a href="{{.URL}}">{{.Username}}</a> {{.Groups}}</li>{{end}}</ul>
+`))
+
+// authorize issues a code for the user named by login_hint, or lists the
+// users to pick from.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	switch {
+	case q.Get("client_id") != p.clientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case redirectURI == "":
+		http.Error(w, "redirect_uri is required", http.StatusBadRequest)
+		return
+	case q.Get("response_type") != "code":
+		redirectError(w, r, redirectURI, q.Get("state"), "unsupported_response_type")
+		return
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		redirectError(w, r, redirectURI, q.Get("state"), "invalid_request")
+		return
+	}
+
+	hint := q.Get("login_hint")
+	if hint == "" && len(p.users) > 1 {
+		type choice struct {
+			URL      string
+			Username string
+			Groups   string
+		}
+		choices := make([]choice, len(p.users))
+		for i, u := range p.users {
+			pick := url.Values{}
+			for k, v := range q {
+				pick[k] = v
+			}
+			pick.Set("login_hint", u.Username)
+			choices[i] = choice{URL: "/authorize?" + pick.Encode(), Username: u.Username, Groups: strings.Join(u.Groups, ", ")}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		pickUser.Execute(w, choices)
+		return
+	}
+
+	user, ok := p.users[0], true
+	if hint != "" {
+		user, ok = p.findUser(hint)
+	}
+	if !ok {
+		redirectError(w, r, redirectURI, q.Get("state"), "access_denied")
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.grants[code] = &grant{
+		user:          user,
+		clientID:      p.clientID,
+		redirectURI:   redirectURI,
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	redirect(w, r, redirectURI, url.Values{"code": {code}, "state": {q.Get("state")}})
+}
+
+// token redeems a code once, checking the PKCE verifier, and returns an ID
+// token.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(id)
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || time.Now().After(g.expiresAt):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown or expired code"})
+		return
+	case clientID != g.clientID || r.PostForm.Get("redirect_uri") != g.redirectURI:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "client or redirect_uri mismatch"})
+		return
+	case base64.RawURLEncoding.EncodeToString(verifier[:]) != g.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier does not match"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                p.issuer,
+		"sub":                g.user.Subject,
+		"aud":                g.clientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(p.tokenTTL).Unix(),
+		"preferred_username": g.user.Username,
+		"email":              g.user.Email,
+		"name":               g.user.Name,
+		"groups":             g.user.Groups,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(p.tokenTTL.Seconds()),
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) findUser(username string) (User, bool) {
+	for _, u := range p.users {
+		if u.Username == username {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+	http.Redirect(w, r, redirectURI+separator+params.Encode(), http.StatusFound)
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI, state, code string) {
+	redirect(w, r, redirectURI, url.Values{"error": {code}, "state": {state}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	raw := make([]byte, 24)
+	rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
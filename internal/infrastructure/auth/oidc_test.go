@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "payslip"
+
+// testProvider serves discovery, JWKS and a token endpoint that answers
+// every code with an ID token carrying claims, signed with signer.
+type testProvider struct {
+	server *httptest.Server
+	key    ed25519.PrivateKey
+	signer ed25519.PrivateKey
+	claims jwt.MapClaims
+	issuer string // issuer in the discovery document, the server URL if empty
+	codes  []string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProvider{key: key, signer: key}
+	p.server = httptest.NewServer(http.HandlerFunc(p.serveHTTP))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *testProvider) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		issuer := p.issuer
+		if issuer == "" {
+			issuer = p.server.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	case "/jwks":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"kid": "test",
+				"x":   base64.RawURLEncoding.EncodeToString(p.key.Public().(ed25519.PublicKey)),
+			}},
+		})
+	case "/token":
+		r.ParseForm()
+		p.codes = append(p.codes, r.PostForm.Get("code"))
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, p.claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(p.signer)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// validClaims returns the claims of an ID token the client accepts for nonce.
+func (p *testProvider) validClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":                p.server.URL,
+		"sub":                "subject-1",
+		"aud":                testClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"name":               "Alice",
+		"groups":             []string{"payroll-admins", "employees"},
+	}
+}
+
+func (p *testProvider) client() *OIDCClient {
+	return NewOIDCClient(p.server.URL, testClientID, "", "http://localhost:8084/login/oidc/callback", "openid", "groups")
+}
+
+func TestOIDCExchangeReturnsIdentity(t *testing.T) {
+	p := newTestProvider(t)
+	p.claims = p.validClaims("nonce-1")
+
+	identity, err := p.client().Exchange(context.Background(), "code-1", "verifier", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if identity.Issuer != p.server.URL || identity.Subject != "subject-1" || identity.Username != "alice" || identity.Email != "alice@example.com" {
+		t.Errorf("identity = %+v", identity)
+	}
+	if strings.Join(identity.Groups, ",") != "payroll-admins,employees" {
+		t.Errorf("groups = %v", identity.Groups)
+	}
+	if len(p.codes) != 1 || p.codes[0] != "code-1" {
+		t.Errorf("token endpoint got codes %v, want [code-1]", p.codes)
+	}
+}
+
+func TestOIDCExchangeRejectsInvalidIDTokens(t *testing.T) {
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		want   string // part of the error
+		modify func(p *testProvider)
+	}{
+		{"wrong nonce", "nonce", func(p *testProvider) { p.claims["nonce"] = "nonce-2" }},
+		{"missing nonce", "nonce", func(p *testProvider) { delete(p.claims, "nonce") }},
+		{"wrong issuer", "issuer", func(p *testProvider) { p.claims["iss"] = "https://attacker.example.com" }},
+		{"wrong audience", "audience", func(p *testProvider) { p.claims["aud"] = "another-client" }},
+		{"expired", "expired", func(p *testProvider) { p.claims["exp"] = time.Now().Add(-2 * time.Minute).Unix() }},
+		{"no expiry", "exp", func(p *testProvider) { delete(p.claims, "exp") }},
+		{"no subject", "subject", func(p *testProvider) { delete(p.claims, "sub") }},
+		{"signed with another key", "signature", func(p *testProvider) { p.signer = otherKey }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t)
+			p.claims = p.validClaims("nonce-1")
+			tt.modify(p)
+
+			identity, err := p.client().Exchange(context.Background(), "code-1", "verifier", "nonce-1")
+			if err == nil {
+				t.Fatalf("Exchange accepted the ID token: %+v", identity)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryRejectsAnotherIssuer(t *testing.T) {
+	p := newTestProvider(t)
+	p.issuer = "https://attacker.example.com"
+	p.claims = p.validClaims("nonce-1")
+
+	if _, err := p.client().AuthCodeURL(context.Background(), "state", "challenge", "nonce-1"); err == nil {
+		t.Error("AuthCodeURL used a discovery document for another issuer")
+	}
+	if _, err := p.client().Exchange(context.Background(), "code-1", "verifier", "nonce-1"); err == nil {
+		t.Error("Exchange used a discovery document for another issuer")
+	}
+	if len(p.codes) != 0 {
+		t.Errorf("sent codes %v to the token endpoint of another issuer", p.codes)
+	}
+}
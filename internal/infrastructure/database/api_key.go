@@ -0,0 +1,44 @@
+package database
+
+import (
+	"payslip/internal/domain/actor"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// RegisterAPIKeyAttribution stamps the API key in the statement context on
+// new rows with an APIKeyID field, such as audit entries, so calls made with
+// a key are attributed to it.
+func RegisterAPIKeyAttribution(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("actor:api_key", func(db *gorm.DB) {
+		if db.Statement.Schema == nil {
+			return
+		}
+		field := db.Statement.Schema.LookUpField("APIKeyID")
+		if field == nil {
+			return
+		}
+		id, ok := actor.APIKeyFromContext(db.Statement.Context)
+		if !ok {
+			return
+		}
+
+		set := func(rv reflect.Value) {
+			if _, zero := field.ValueOf(db.Statement.Context, rv); zero {
+				if err := field.Set(db.Statement.Context, rv, &id); err != nil {
+					db.AddError(err)
+				}
+			}
+		}
+		rv := db.Statement.ReflectValue
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				set(reflect.Indirect(rv.Index(i)))
+			}
+		case reflect.Struct:
+			set(rv)
+		}
+	})
+}
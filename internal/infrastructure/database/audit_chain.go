@@ -0,0 +1,126 @@
+package database
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RegisterAuditChain links every new audit entry to the one before it in its
+// tenant, so editing or deleting an entry breaks the chain from there on.
+// Entries are signed with key when it is set. The chain is extended under a
+// transaction-scoped advisory lock per tenant, which makes concurrent audit
+// writes in one tenant wait for each other's transactions to finish.
+func RegisterAuditChain(db *gorm.DB, key ed25519.PrivateKey) error {
+	return db.Callback().Create().Before("gorm:create").After("audit:changes").Register("audit:chain", func(db *gorm.DB) {
+		if skip, _ := db.Get(skipAuditChainKey); skip == true {
+			return
+		}
+		if entries := auditLogsOf(db.Statement.Dest); len(entries) > 0 {
+			db.AddError(chainAuditLogs(db, entries, key))
+		}
+	})
+}
+
+const skipAuditChainKey = "audit:skip_chain"
+
+// WithoutAuditChain returns db for writing audit entries that already have
+// their place in the chain, such as those restored from an archive.
+func WithoutAuditChain(db *gorm.DB) *gorm.DB {
+	return db.Set(skipAuditChainKey, true)
+}
+
+// chainAuditLogs appends entries to their tenants' chains in the order given.
+// Each tenant's chain is locked and its last entry read once per batch.
+func chainAuditLogs(db *gorm.DB, entries []*models.AuditLog, key ed25519.PrivateKey) error {
+	tails := map[uuid.UUID]*models.AuditLog{}
+	tx := db.Session(&gorm.Session{NewDB: true})
+	for _, entry := range entries {
+		if entry.ID == uuid.Nil {
+			entry.ID = uuid.New()
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		entry.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+		if entry.Outcome == "" {
+			entry.Outcome = models.AuditOutcomeSuccess
+		}
+
+		last, ok := tails[entry.TenantID]
+		if !ok {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "audit_logs:"+entry.TenantID.String()).Error; err != nil {
+				return fmt.Errorf("failed to lock audit chain: %w", err)
+			}
+			last = &models.AuditLog{}
+			err := tx.Select("sequence", "hash").
+				Where("tenant_id = ? AND sequence IS NOT NULL", entry.TenantID).
+				Order("sequence DESC").
+				Take(last).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to read audit chain: %w", err)
+			}
+		}
+
+		sequence := int64(1)
+		if last.Sequence != nil {
+			sequence = *last.Sequence + 1
+		}
+		entry.Sequence = &sequence
+		entry.PrevHash = last.Hash
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return err
+		}
+		entry.Hash = hash
+		entry.Signature = ""
+		if key != nil {
+			entry.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(hash)))
+		}
+		tails[entry.TenantID] = entry
+	}
+	return nil
+}
+
+// chainExistingAuditLogs adds entries written before the chain existed to the
+// end of their tenant's chain, oldest first. They are not signed.
+func chainExistingAuditLogs(db *gorm.DB) error {
+	var tenantIDs []uuid.UUID
+	if err := db.Model(&models.AuditLog{}).Where("sequence IS NULL").Distinct().Pluck("tenant_id", &tenantIDs).Error; err != nil {
+		return err
+	}
+	for _, tenantID := range tenantIDs {
+		for {
+			done := false
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var entries []*models.AuditLog
+				if err := tx.Where("tenant_id = ? AND sequence IS NULL", tenantID).Order("created_at, id").Limit(500).Find(&entries).Error; err != nil {
+					return err
+				}
+				if err := chainAuditLogs(tx, entries, nil); err != nil {
+					return err
+				}
+				for _, entry := range entries {
+					if err := tx.Model(entry).Select("sequence", "prev_hash", "hash", "signature").Updates(entry).Error; err != nil {
+						return err
+					}
+				}
+				done = len(entries) < 500
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to chain audit logs of tenant %s: %w", tenantID, err)
+			}
+			if done {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"payslip/internal/domain/models"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type auditedTable struct {
+	model  func() interface{}
+	redact map[string]bool // columns never copied into the audit log
+}
+
+// auditedTables maps the table names used in audit entries to the model of
+// the record they describe.
+var auditedTables = map[string]auditedTable{
+	"user":              {model: func() interface{} { return &models.User{} }, redact: map[string]bool{"password": true}},
+	"salary_change":     {model: func() interface{} { return &models.SalaryChange{} }},
+	"department":        {model: func() interface{} { return &models.Department{} }},
+	"cost_center":       {model: func() interface{} { return &models.CostCenter{} }},
+	"tenant":            {model: func() interface{} { return &models.Tenant{} }},
+	"api_key":           {model: func() interface{} { return &models.APIKey{} }, redact: map[string]bool{"key_hash": true}},
+	"attendance_period": {model: func() interface{} { return &models.AttendancePeriod{} }},
+	"pay_schedule":      {model: func() interface{} { return &models.PaySchedule{} }},
+	"attendance":        {model: func() interface{} { return &models.Attendance{} }},
+	"overtime":          {model: func() interface{} { return &models.Overtime{} }},
+	"reimbursement":     {model: func() interface{} { return &models.Reimbursement{} }},
+	"payroll":           {model: func() interface{} { return &models.Payroll{} }},
+	"office_network":    {model: func() interface{} { return &models.OfficeNetwork{} }},
+	"geofence":          {model: func() interface{} { return &models.Geofence{} }},
+}
+
+// RegisterAuditChanges fills in the Changes and Snapshot of new audit
+// entries. The record an entry is about is read back in the same connection
+// as the entry is written, and compared to the snapshot in the record's
+// previous entry. Services therefore only describe what they did, the
+// before and after values are captured here for all of them.
+//
+// Entries written to the audit outbox get theirs when they enter the outbox,
+// in the transaction of the change, and keep them when they are relayed.
+func RegisterAuditChanges(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("audit:changes", func(db *gorm.DB) {
+		if skip, _ := db.Get(skipAuditChangesKey); skip == true {
+			return
+		}
+		var entries []*models.AuditLog
+		switch dest := db.Statement.Dest.(type) {
+		case *models.AuditOutboxEntry:
+			entries = []*models.AuditLog{&dest.Entry}
+		case []*models.AuditOutboxEntry:
+			for _, queued := range dest {
+				entries = append(entries, &queued.Entry)
+			}
+		default:
+			entries = auditLogsOf(dest)
+		}
+
+		// Snapshots taken earlier in the batch, newer than any stored one.
+		latest := map[auditRecord]models.AuditValues{}
+		for _, entry := range entries {
+			if err := recordAuditChanges(db, entry, latest); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	})
+}
+
+const skipAuditChangesKey = "audit:skip_changes"
+
+// WithoutAuditChanges returns db for writing audit entries whose Changes and
+// Snapshot were already filled in, such as those relayed from the outbox.
+func WithoutAuditChanges(db *gorm.DB) *gorm.DB {
+	return db.Set(skipAuditChangesKey, true)
+}
+
+// auditLogsOf returns the audit entries a create statement writes, if any.
+func auditLogsOf(dest interface{}) []*models.AuditLog {
+	switch dest := dest.(type) {
+	case *models.AuditLog:
+		return []*models.AuditLog{dest}
+	case []*models.AuditLog:
+		return dest
+	case []models.AuditLog:
+		entries := make([]*models.AuditLog, len(dest))
+		for i := range dest {
+			entries[i] = &dest[i]
+		}
+		return entries
+	}
+	return nil
+}
+
+type auditRecord struct {
+	table string
+	id    uuid.UUID
+}
+
+func recordAuditChanges(db *gorm.DB, entry *models.AuditLog, latest map[auditRecord]models.AuditValues) error {
+	table, ok := auditedTables[entry.TableName]
+	if !ok || entry.RecordID == uuid.Nil || entry.Changes != nil {
+		return nil
+	}
+	tx := db.Session(&gorm.Session{NewDB: true})
+
+	var current models.AuditValues
+	record := table.model()
+	result := tx.Where("id = ?", entry.RecordID).Take(record)
+	switch {
+	case result.Error == nil:
+		values, err := snapshotRecord(result, record, table.redact)
+		if err != nil {
+			return err
+		}
+		current = values
+	case !errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return result.Error
+	}
+
+	key := auditRecord{table: entry.TableName, id: entry.RecordID}
+	previous, ok := latest[key]
+	if !ok {
+		var err error
+		if previous, err = previousSnapshot(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	changes := diffAuditValues(previous, current)
+	if len(changes) == 0 {
+		return nil
+	}
+	entry.Changes = changes
+	entry.Snapshot = current
+	if entry.Snapshot == nil {
+		entry.Snapshot = models.AuditValues{}
+	}
+	latest[key] = entry.Snapshot
+	return nil
+}
+
+// previousSnapshot returns the snapshot in the latest entry about the same
+// record. Entries still in the outbox are newer than those in the audit log.
+func previousSnapshot(tx *gorm.DB, entry *models.AuditLog) (models.AuditValues, error) {
+	var queued models.AuditOutboxEntry
+	err := tx.Select("entry").
+		Where("entry->>'TableName' = ? AND entry->>'RecordID' = ? AND entry->'Snapshot' <> 'null'::jsonb", entry.TableName, entry.RecordID.String()).
+		Order("created_at DESC, id DESC").
+		Take(&queued).Error
+	if err == nil {
+		return queued.Entry.Snapshot, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var previous models.AuditLog
+	err = tx.Select("snapshot").
+		Where("table_name = ? AND record_id = ? AND snapshot IS NOT NULL", entry.TableName, entry.RecordID).
+		Order("created_at DESC, id DESC").
+		Take(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return previous.Snapshot, nil
+}
+
+// snapshotRecord returns the columns of record as they read back from JSON,
+// so they compare equal to a snapshot loaded from the database.
+func snapshotRecord(result *gorm.DB, record interface{}, redact map[string]bool) (models.AuditValues, error) {
+	rv := reflect.ValueOf(record).Elem()
+	columns := map[string]interface{}{}
+	for _, field := range result.Statement.Schema.Fields {
+		if field.DBName == "" || redact[field.DBName] {
+			continue
+		}
+		columns[field.DBName], _ = field.ValueOf(result.Statement.Context, rv)
+	}
+
+	raw, err := json.Marshal(columns)
+	if err != nil {
+		return nil, err
+	}
+	var values models.AuditValues
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func diffAuditValues(old, new models.AuditValues) models.AuditChanges {
+	changes := models.AuditChanges{}
+	for column, value := range new {
+		before, ok := old[column]
+		if !ok && value == nil {
+			continue
+		}
+		if !ok || !reflect.DeepEqual(before, value) {
+			changes[column] = models.AuditChange{Old: old[column], New: value}
+		}
+	}
+	for column, before := range old {
+		if _, ok := new[column]; !ok {
+			changes[column] = models.AuditChange{Old: before}
+		}
+	}
+	return changes
+}
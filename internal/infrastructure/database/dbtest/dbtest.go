@@ -0,0 +1,209 @@
+// Package dbtest opens a GORM database for tests that records the SQL it is
+// sent instead of running it, so repositories and the callbacks registered
+// by package database can be tested without a PostgreSQL server.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"payslip/internal/infrastructure/database"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is one SQL statement sent to the database, with its arguments
+// after driver conversion, so UUIDs appear as strings.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// InsertValue returns the value given for column by a single-row INSERT.
+func (s Statement) InsertValue(column string) (driver.Value, bool) {
+	if !strings.HasPrefix(s.SQL, "INSERT INTO") {
+		return nil, false
+	}
+	start := strings.Index(s.SQL, "(")
+	end := strings.Index(s.SQL, ")")
+	if start < 0 || end < start {
+		return nil, false
+	}
+	for i, name := range strings.Split(s.SQL[start+1:end], ",") {
+		if strings.Trim(name, `" `) == column && i < len(s.Args) {
+			return s.Args[i], true
+		}
+	}
+	return nil, false
+}
+
+// HasArg reports whether value is one of the statement's arguments.
+func (s Statement) HasArg(value driver.Value) bool {
+	for _, arg := range s.Args {
+		if arg == value {
+			return true
+		}
+	}
+	return false
+}
+
+type result struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// Recorder keeps the statements sent to a database opened by Open and the
+// rows its queries return.
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	results    []result
+}
+
+// Returns makes queries containing match return rows with columns. Queries
+// matching nothing return no rows.
+func (r *Recorder) Returns(match string, columns []string, rows ...[]driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result{match: match, columns: columns, rows: rows})
+}
+
+// Statements returns the statements containing match, in the order they
+// were sent.
+func (r *Recorder) Statements(match string) []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var statements []Statement
+	for _, s := range r.statements {
+		if strings.Contains(s.SQL, match) {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+func (r *Recorder) record(query string, args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, Statement{SQL: query, Args: values})
+}
+
+func (r *Recorder) rowsFor(query string) *rows {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, res := range r.results {
+		if strings.Contains(query, res.match) {
+			return &rows{columns: res.columns, values: res.rows}
+		}
+	}
+	return &rows{}
+}
+
+// Open returns a database using the PostgreSQL dialect, scoped by tenant
+// like database.NewGORM, and the Recorder behind it.
+func Open(t testing.TB) (*gorm.DB, *Recorder) {
+	t.Helper()
+	recorder := &Recorder{}
+	sqlDB := sql.OpenDB(connector{recorder: recorder})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := database.RegisterTenantScope(db); err != nil {
+		t.Fatalf("failed to register tenant scope: %v", err)
+	}
+	return db, recorder
+}
+
+type connector struct {
+	recorder *Recorder
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{recorder: c.recorder}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return recordingDriver{}
+}
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dbtest: open the database with dbtest.Open")
+}
+
+type conn struct {
+	recorder *Recorder
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("dbtest: prepared statements are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.recorder.record(query, args)
+	return c.recorder.rowsFor(query), nil
+}
+
+// ExecContext reports one affected row, so updates are not retried as
+// inserts.
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.recorder.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
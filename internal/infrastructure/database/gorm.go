@@ -1,25 +1,57 @@
 package database
 
 import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
 	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
-func NewGORM(dsn string) *gorm.DB {
+// NewGORM connects to the database and registers the tenant and audit
+// callbacks. New audit entries are signed with auditKey unless it is nil.
+func NewGORM(dsn string, auditKey ed25519.PrivateKey) *gorm.DB {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		panic("Failed to connect to database: " + err.Error())
 	}
+	if err := RegisterTenantScope(db); err != nil {
+		panic("Failed to register tenant scope: " + err.Error())
+	}
+	if err := RegisterAPIKeyAttribution(db); err != nil {
+		panic("Failed to register API key attribution: " + err.Error())
+	}
+	if err := RegisterAuditChanges(db); err != nil {
+		panic("Failed to register audit changes: " + err.Error())
+	}
+	if err := RegisterAuditChain(db, auditKey); err != nil {
+		panic("Failed to register audit chain: " + err.Error())
+	}
 	return db
 }
 
+// Migrate creates and updates the schema and seeds the default roles of
+// every tenant.
 func Migrate(db *gorm.DB) {
+	db = db.WithContext(tenant.System(context.Background()))
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+	db.AutoMigrate(&models.Tenant{})
+	db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Tenant{ID: models.DefaultTenantID, Name: "Default"})
+	// Department names and cost center codes are unique per tenant now.
+	for table, index := range map[string]string{"departments": "idx_departments_name", "cost_centers": "idx_cost_centers_code"} {
+		if db.Migrator().HasIndex(table, index) {
+			db.Migrator().DropIndex(table, index)
+		}
+	}
+	migrateSharedRoles(db)
 	db.AutoMigrate(
 		&models.User{},
 		&models.AttendancePeriod{},
@@ -28,5 +60,134 @@ func Migrate(db *gorm.DB) {
 		&models.Reimbursement{},
 		&models.Payroll{},
 		&models.AuditLog{},
+		&models.OfficeNetwork{},
+		&models.Geofence{},
+		&models.PaySchedule{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.SessionRevocation{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.PasswordResetToken{},
+		&models.LoginAttempt{},
+		&models.MFACredential{},
+		&models.MFARecoveryCode{},
+		&models.SalaryChange{},
+		&models.Department{},
+		&models.CostCenter{},
+		&models.OIDCLoginState{},
+		&models.APIKey{},
+		&models.AuditOutboxEntry{},
+		&models.AuditArchive{},
+		&models.AuditChainGap{},
 	)
+	if err := chainExistingAuditLogs(db); err != nil {
+		panic("Failed to chain existing audit logs: " + err.Error())
+	}
+	seedRoles(db)
+}
+
+// seededPermission records a default grant that SeedRoles has applied once
+// to a tenant, so permissions an admin later removes are not granted again
+// on restart while permissions added to the defaults in a new release are.
+type seededPermission struct {
+	TenantID   uuid.UUID `gorm:"type:uuid;primaryKey;default:'00000000-0000-0000-0000-000000000001'"`
+	RoleName   string    `gorm:"primaryKey;size:20"`
+	Permission string    `gorm:"primaryKey;size:100"`
+}
+
+func (seededPermission) TableName() string {
+	return "seeded_role_permissions"
+}
+
+// seedRoles creates the default roles of every tenant.
+func seedRoles(db *gorm.DB) {
+	db.AutoMigrate(&seededPermission{})
+	var tenants []models.Tenant
+	if err := db.Find(&tenants).Error; err != nil {
+		panic("Failed to find tenants: " + err.Error())
+	}
+	for _, t := range tenants {
+		if err := SeedRoles(db, t.ID); err != nil {
+			panic("Failed to seed roles of tenant " + t.Name + ": " + err.Error())
+		}
+	}
+}
+
+// SeedRoles creates the default roles of the tenant and grants their default
+// permissions.
+func SeedRoles(db *gorm.DB, tenantID uuid.UUID) error {
+	for name, permissions := range models.DefaultRolePermissions {
+		role := &models.Role{TenantID: tenantID, Name: name, Description: "Default " + name + " role"}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(role).Error; err != nil {
+			return err
+		}
+		for _, p := range permissions {
+			result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seededPermission{TenantID: tenantID, RoleName: name, Permission: p})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RolePermission{TenantID: tenantID, RoleName: name, Permission: p}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateSharedRoles scopes the roles tables by tenant. Roles used to be
+// shared by all tenants, so every tenant gets a copy of each of them.
+func migrateSharedRoles(db *gorm.DB) {
+	if !db.Migrator().HasTable(&models.Role{}) || db.Migrator().HasColumn(&models.Role{}, "TenantID") {
+		return
+	}
+	tables := []struct{ name, key string }{
+		{"roles", "name"},
+		{"role_permissions", "role_name, permission"},
+		{"seeded_role_permissions", "role_name, permission"},
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE role_permissions DROP CONSTRAINT IF EXISTS fk_roles_permissions").Error; err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if !tx.Migrator().HasTable(table.name) {
+				continue
+			}
+			statements := []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN tenant_id uuid NOT NULL DEFAULT '%s'", table.name, models.DefaultTenantID),
+				fmt.Sprintf("ALTER TABLE %[1]s DROP CONSTRAINT %[1]s_pkey, ADD PRIMARY KEY (tenant_id, %[2]s)", table.name, table.key),
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+		}
+		copies := []string{
+			`INSERT INTO roles (tenant_id, name, description, created_at, updated_at, created_by, updated_by)
+			SELECT t.id, r.name, r.description, r.created_at, r.updated_at, r.created_by, r.updated_by
+			FROM roles r CROSS JOIN tenants t WHERE r.tenant_id = ? AND t.id <> ?`,
+			`INSERT INTO role_permissions (tenant_id, role_name, permission)
+			SELECT t.id, p.role_name, p.permission
+			FROM role_permissions p CROSS JOIN tenants t WHERE p.tenant_id = ? AND t.id <> ?`,
+		}
+		if tx.Migrator().HasTable("seeded_role_permissions") {
+			copies = append(copies, `INSERT INTO seeded_role_permissions (tenant_id, role_name, permission)
+			SELECT t.id, p.role_name, p.permission
+			FROM seeded_role_permissions p CROSS JOIN tenants t WHERE p.tenant_id = ? AND t.id <> ?`)
+		}
+		for _, statement := range copies {
+			if err := tx.Exec(statement, models.DefaultTenantID, models.DefaultTenantID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic("Failed to scope roles by tenant: " + err.Error())
+	}
 }
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// RegisterTenantScope restricts every statement on a model with a TenantID
+// field to the tenant in the statement context, and stamps that tenant on
+// new rows. Rows cannot be created in or moved to another tenant.
+// Statements with a tenant.System context are not scoped and create rows in
+// the default tenant unless the row names its tenant. Statements with
+// neither fail with tenant.ErrNoTenant.
+// Raw SQL is never scoped and must filter by tenant itself.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", tenantCreate); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", tenantWhere); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", tenantUpdate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", tenantWhere); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", tenantWhere)
+}
+
+// tenantField returns the TenantID field of the statement's model and the
+// tenant to scope the statement to. ok is false when the statement is not
+// scoped, after adding tenant.ErrNoTenant unless its context is a system
+// context.
+func tenantField(db *gorm.DB) (*schema.Field, uuid.UUID, bool) {
+	if db.Statement.Schema == nil {
+		return nil, uuid.Nil, false
+	}
+	field := db.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return nil, uuid.Nil, false
+	}
+	ctx := db.Statement.Context
+	id, ok := tenant.FromContext(ctx)
+	if !ok && !tenant.IsSystem(ctx) {
+		db.AddError(fmt.Errorf("%w: %s", tenant.ErrNoTenant, db.Statement.Table))
+	}
+	return field, id, ok
+}
+
+func tenantWhere(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+func tenantUpdate(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	// Save writes every column, so a row carrying another tenant's ID would
+	// be moved into that tenant.
+	if rv := reflect.Indirect(db.Statement.ReflectValue); rv.Kind() == reflect.Struct {
+		if value, zero := field.ValueOf(db.Statement.Context, rv); !zero && value.(uuid.UUID) != id {
+			db.AddError(fmt.Errorf("cannot move %s to another tenant", db.Statement.Table))
+			return
+		}
+	}
+	tenantWhere(db)
+}
+
+func tenantCreate(db *gorm.DB) {
+	field, id, scoped := tenantField(db)
+	if field == nil || db.Error != nil {
+		return
+	}
+	// Rows created by system work, such as failed logins of unknown
+	// usernames, belong to the default tenant unless they name one.
+	if !scoped {
+		id = models.DefaultTenantID
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setTenant(db, field, reflect.Indirect(rv.Index(i)), id, scoped)
+		}
+	case reflect.Struct:
+		setTenant(db, field, rv, id, scoped)
+	}
+}
+
+func setTenant(db *gorm.DB, field *schema.Field, rv reflect.Value, id uuid.UUID, scoped bool) {
+	ctx := db.Statement.Context
+	value, zero := field.ValueOf(ctx, rv)
+	if zero {
+		if err := field.Set(ctx, rv, id); err != nil {
+			db.AddError(err)
+		}
+		return
+	}
+	if scoped && value.(uuid.UUID) != id {
+		db.AddError(fmt.Errorf("cannot create %s for another tenant", db.Statement.Table))
+	}
+}
@@ -0,0 +1,66 @@
+// internal/infrastructure/notify/notify.go
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// New returns the notifier selected by kind, 'log' or 'file'. path is only
+// used by the file notifier.
+func New(kind, path string) (interfaces.Notifier, error) {
+	switch kind {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "file":
+		if path == "" {
+			return nil, fmt.Errorf("notifier file path is empty")
+		}
+		return NewFileNotifier(path), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
+
+// LogNotifier writes notifications to the server log. It is meant for local
+// development.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, user *models.User, subject, body string) error {
+	log.Printf("Notification to %s (%s): %s: %s", user.Username, user.ID, subject, body)
+	return nil
+}
+
+// FileNotifier appends notifications to a file.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, user *models.User, subject, body string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\tto=%s\tuser_id=%s\tsubject=%s\n%s\n\n",
+		time.Now().Format(time.RFC3339), user.Username, user.ID, subject, body)
+	if err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return conn(ctx, r.db).Create(key).Error
+}
+
+func (r *APIKeyRepository) FindAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := conn(ctx, r.db).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to find API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := conn(ctx, r.db).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return nil, fmt.Errorf("API key not found: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time, ipAddress string, every time.Duration) error {
+	return conn(ctx, r.db).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-every)).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ipAddress}).Error
+}
@@ -19,39 +19,128 @@ func NewAttendanceRepository(db *gorm.DB) *AttendanceRepository {
 }
 
 func (r *AttendanceRepository) CreatePeriod(ctx context.Context, period *models.AttendancePeriod) error {
-	return r.db.WithContext(ctx).Create(period).Error
+	return conn(ctx, r.db).Create(period).Error
 }
 
 func (r *AttendanceRepository) FindPeriodByID(ctx context.Context, id uuid.UUID) (*models.AttendancePeriod, error) {
 	var period models.AttendancePeriod
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&period).Error; err != nil {
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&period).Error; err != nil {
 		return nil, fmt.Errorf("period not found: %w", err)
 	}
 	return &period, nil
 }
 
+func (r *AttendanceRepository) FindPeriods(ctx context.Context, from, to *time.Time, limit, offset int) ([]*models.AttendancePeriod, int64, error) {
+	query := conn(ctx, r.db).Model(&models.AttendancePeriod{})
+	if from != nil {
+		query = query.Where("end_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("start_date <= ?", *to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count periods: %w", err)
+	}
+
+	var periods []*models.AttendancePeriod
+	if err := query.Order("start_date DESC").Limit(limit).Offset(offset).Find(&periods).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find periods: %w", err)
+	}
+	return periods, total, nil
+}
+
+func (r *AttendanceRepository) FindPeriodsContainingDate(ctx context.Context, date time.Time) ([]*models.AttendancePeriod, error) {
+	var periods []*models.AttendancePeriod
+	if err := conn(ctx, r.db).Where("start_date <= ? AND end_date >= ?", date, date).Order("start_date").Find(&periods).Error; err != nil {
+		return nil, fmt.Errorf("failed to find periods: %w", err)
+	}
+	return periods, nil
+}
+
+func (r *AttendanceRepository) FindOverlappingPeriods(ctx context.Context, start, end time.Time, excludeID uuid.UUID) ([]*models.AttendancePeriod, error) {
+	var periods []*models.AttendancePeriod
+	if err := conn(ctx, r.db).Where("start_date <= ? AND end_date >= ? AND id <> ?", end, start, excludeID).Order("start_date").Find(&periods).Error; err != nil {
+		return nil, fmt.Errorf("failed to find periods: %w", err)
+	}
+	return periods, nil
+}
+
+func (r *AttendanceRepository) WithPeriodLock(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, func(tx context.Context) error {
+		if err := conn(tx, r.db).Exec("SELECT pg_advisory_xact_lock(?)", periodGenerationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire period lock: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+func (r *AttendanceRepository) UpdatePeriod(ctx context.Context, period *models.AttendancePeriod) error {
+	return conn(ctx, r.db).Save(period).Error
+}
+
+func (r *AttendanceRepository) DeletePeriod(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Where("id = ?", id).Delete(&models.AttendancePeriod{}).Error
+}
+
+func (r *AttendanceRepository) HasSubmissions(ctx context.Context, periodID uuid.UUID) bool {
+	for _, model := range []interface{}{&models.Attendance{}, &models.Overtime{}, &models.Reimbursement{}} {
+		var count int64
+		conn(ctx, r.db).Model(model).Where("period_id = ?", periodID).Count(&count)
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *AttendanceRepository) CreateAttendance(ctx context.Context, attendance *models.Attendance) error {
-	return r.db.WithContext(ctx).Create(attendance).Error
+	return conn(ctx, r.db).Create(attendance).Error
 }
 
 func (r *AttendanceRepository) FindAttendanceByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time, periodID uuid.UUID) (*models.Attendance, error) {
 	var attendance models.Attendance
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND date = ? AND period_id = ?", userID, date, periodID).First(&attendance).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND date = ? AND period_id = ? AND (review_status IS NULL OR review_status <> ?)", userID, date, periodID, "rejected").First(&attendance).Error; err != nil {
+		return nil, fmt.Errorf("attendance not found: %w", err)
+	}
+	return &attendance, nil
+}
+
+func (r *AttendanceRepository) FindAttendanceByID(ctx context.Context, id uuid.UUID) (*models.Attendance, error) {
+	var attendance models.Attendance
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&attendance).Error; err != nil {
 		return nil, fmt.Errorf("attendance not found: %w", err)
 	}
 	return &attendance, nil
 }
 
+func (r *AttendanceRepository) FindAttendancesByReviewStatus(ctx context.Context, status string) ([]*models.Attendance, error) {
+	var attendances []*models.Attendance
+	if err := conn(ctx, r.db).Where("review_status = ?", status).Order("date").Find(&attendances).Error; err != nil {
+		return nil, fmt.Errorf("failed to find attendances: %w", err)
+	}
+	return attendances, nil
+}
+
+func (r *AttendanceRepository) UpdateAttendance(ctx context.Context, attendance *models.Attendance) error {
+	return conn(ctx, r.db).Save(attendance).Error
+}
+
 func (r *AttendanceRepository) CreateOvertime(ctx context.Context, overtime *models.Overtime) error {
-	return r.db.WithContext(ctx).Create(overtime).Error
+	return conn(ctx, r.db).Create(overtime).Error
 }
 
 func (r *AttendanceRepository) CreateReimbursement(ctx context.Context, reimbursement *models.Reimbursement) error {
-	return r.db.WithContext(ctx).Create(reimbursement).Error
+	return conn(ctx, r.db).Create(reimbursement).Error
+}
+
+func (r *AttendanceRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
 }
 
 func (r *AttendanceRepository) IsPayrollProcessed(ctx context.Context, periodID uuid.UUID) bool {
 	var count int64
-	r.db.WithContext(ctx).Model(&models.Payroll{}).Where("period_id = ?", periodID).Count(&count)
+	conn(ctx, r.db).Model(&models.Payroll{}).Where("period_id = ?", periodID).Count(&count)
 	return count > 0
 }
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/database/dbtest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A rejected attendance must not stop the employee from submitting the date
+// again.
+func TestFindAttendanceByUserAndDateSkipsRejected(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+
+	repo.FindAttendanceByUserAndDate(tenant.WithID(context.Background(), tenantA), uuid.New(), time.Now(), uuid.New())
+
+	queries := recorder.Statements(`FROM "attendances"`)
+	if len(queries) != 1 || !strings.Contains(queries[0].SQL, "review_status <> ") || !queries[0].HasArg("rejected") {
+		t.Errorf("duplicate lookup = %v, want one that skips rejected attendance", queries)
+	}
+}
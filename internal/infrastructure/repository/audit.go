@@ -3,6 +3,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"payslip/internal/domain/interfaces"
 	"payslip/internal/domain/models"
 
 	"gorm.io/gorm"
@@ -17,5 +20,78 @@ func NewAuditRepository(db *gorm.DB) *AuditRepository {
 }
 
 func (r *AuditRepository) Create(ctx context.Context, audit *models.AuditLog) error {
-	return r.db.WithContext(ctx).Create(audit).Error
+	return conn(ctx, r.db).Create(audit).Error
+}
+
+// auditInsertBatch is the number of audit entries per INSERT statement.
+const auditInsertBatch = 500
+
+func (r *AuditRepository) CreateBatch(ctx context.Context, audits []*models.AuditLog) error {
+	if len(audits) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).CreateInBatches(audits, auditInsertBatch).Error
+}
+
+func (r *AuditRepository) FindAuditLogs(ctx context.Context, filter interfaces.AuditFilter, cursor *interfaces.AuditCursor, limit int) ([]*models.AuditLog, error) {
+	query := conn(ctx, r.db).Model(&models.AuditLog{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.APIKeyID != nil {
+		query = query.Where("api_key_id = ?", *filter.APIKeyID)
+	}
+	if filter.TableName != "" {
+		query = query.Where("table_name = ?", filter.TableName)
+	}
+	if filter.RecordID != nil {
+		query = query.Where("record_id = ?", *filter.RecordID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Field != "" {
+		query = query.Where("jsonb_exists(changes, ?)", filter.Field)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	var logs []*models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+func (r *AuditRepository) FindAuditChain(ctx context.Context, afterSequence int64, limit int) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	if err := conn(ctx, r.db).Where("sequence > ?", afterSequence).Order("sequence").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+func (r *AuditRepository) FindAuditChainGap(ctx context.Context, fromSequence int64) (*models.AuditChainGap, error) {
+	var gap models.AuditChainGap
+	err := conn(ctx, r.db).Where("from_sequence = ?", fromSequence).Take(&gap).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit chain gap: %w", err)
+	}
+	return &gap, nil
 }
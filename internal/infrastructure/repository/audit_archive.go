@@ -0,0 +1,113 @@
+// internal/infrastructure/repository/audit_archive.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/interfaces"
+	"payslip/internal/domain/models"
+	"payslip/internal/infrastructure/database"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuditArchiveRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditArchiveRepository(db *gorm.DB) *AuditArchiveRepository {
+	return &AuditArchiveRepository{db: db}
+}
+
+func (r *AuditArchiveRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
+
+func (r *AuditArchiveRepository) LockExpiredAuditLogs(ctx context.Context, retention interfaces.AuditRetention, now time.Time, limit int) ([]*models.AuditLog, error) {
+	// A NULL cutoff keeps the entry forever.
+	cutoff := func(keep time.Duration) interface{} {
+		if keep <= 0 {
+			return nil
+		}
+		return now.Add(-keep)
+	}
+	var expr strings.Builder
+	var args []interface{}
+	if len(retention.Rules) > 0 {
+		expr.WriteString("CASE")
+		for _, rule := range retention.Rules {
+			var conds []string
+			if rule.TableName != "" {
+				conds = append(conds, "table_name = ?")
+				args = append(args, rule.TableName)
+			}
+			if rule.Action != "" {
+				conds = append(conds, "action = ?")
+				args = append(args, rule.Action)
+			}
+			expr.WriteString(" WHEN " + strings.Join(conds, " AND ") + " THEN CAST(? AS timestamptz)")
+			args = append(args, cutoff(rule.Keep))
+		}
+		expr.WriteString(" ELSE CAST(? AS timestamptz) END")
+	} else {
+		expr.WriteString("CAST(? AS timestamptz)")
+	}
+	args = append(args, cutoff(retention.Default))
+
+	query := conn(ctx, r.db).
+		Where("sequence IS NOT NULL").
+		Where("created_at < "+expr.String(), args...).
+		Where("sequence < (SELECT MAX(tail.sequence) FROM audit_logs tail WHERE tail.tenant_id = audit_logs.tenant_id)")
+	if retention.RestoreHold > 0 {
+		query = query.Where("restored_at IS NULL OR restored_at < ?", now.Add(-retention.RestoreHold))
+	}
+
+	var logs []*models.AuditLog
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Order("tenant_id, sequence").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find expired audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+func (r *AuditArchiveRepository) ArchiveAuditLogs(ctx context.Context, archive *models.AuditArchive, gaps []*models.AuditChainGap, ids []uuid.UUID) error {
+	tx := conn(ctx, r.db)
+	if err := tx.Create(archive).Error; err != nil {
+		return fmt.Errorf("failed to create audit archive: %w", err)
+	}
+	if len(gaps) > 0 {
+		if err := tx.Create(gaps).Error; err != nil {
+			return fmt.Errorf("failed to create audit chain gaps: %w", err)
+		}
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&models.AuditLog{}).Error; err != nil {
+		return fmt.Errorf("failed to delete archived audit logs: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditArchiveRepository) RestoreAuditLogs(ctx context.Context, archiveID uuid.UUID, entries []*models.AuditLog, at time.Time) (int, error) {
+	for _, entry := range entries {
+		entry.RestoredAt = &at
+	}
+	tx := conn(ctx, r.db)
+	result := database.WithoutAuditChain(database.WithoutAuditChanges(tx)).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(entries, auditInsertBatch)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to restore audit logs: %w", result.Error)
+	}
+	if err := tx.Where("archive_id = ?", archiveID).Delete(&models.AuditChainGap{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete audit chain gaps: %w", err)
+	}
+	if err := tx.Model(&models.AuditArchive{}).Where("id = ?", archiveID).Update("restored_at", at).Error; err != nil {
+		return 0, fmt.Errorf("failed to mark audit archive restored: %w", err)
+	}
+	return int(result.RowsAffected), nil
+}
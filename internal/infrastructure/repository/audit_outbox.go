@@ -0,0 +1,87 @@
+// internal/infrastructure/repository/audit_outbox.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/actor"
+	"payslip/internal/domain/models"
+	"payslip/internal/infrastructure/database"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditOutboxRepository writes audit entries to the audit_outbox_entries
+// table in the caller's transaction, see interfaces.AuditOutbox.
+type AuditOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditOutboxRepository(db *gorm.DB) *AuditOutboxRepository {
+	return &AuditOutboxRepository{db: db}
+}
+
+func (r *AuditOutboxRepository) Create(ctx context.Context, audit *models.AuditLog) error {
+	return r.CreateBatch(ctx, []*models.AuditLog{audit})
+}
+
+func (r *AuditOutboxRepository) CreateBatch(ctx context.Context, audits []*models.AuditLog) error {
+	if len(audits) == 0 {
+		return nil
+	}
+	queued := make([]*models.AuditOutboxEntry, len(audits))
+	for i, audit := range audits {
+		if audit.ID == uuid.Nil {
+			audit.ID = uuid.New()
+		}
+		if audit.CreatedAt.IsZero() {
+			audit.CreatedAt = time.Now()
+		}
+		// The relay writes the entry without the caller's context.
+		actor.Attribute(ctx, audit)
+		queued[i] = &models.AuditOutboxEntry{ID: audit.ID, Entry: *audit}
+	}
+	if err := conn(ctx, r.db).CreateInBatches(queued, auditInsertBatch).Error; err != nil {
+		return fmt.Errorf("failed to queue audit logs: %w", err)
+	}
+	return nil
+}
+
+// RelayAuditOutbox moves the oldest queued entries into the audit log and
+// deletes them from the outbox in one transaction. Rows being relayed by
+// another call are skipped, so several relays can run at once.
+func (r *AuditOutboxRepository) RelayAuditOutbox(ctx context.Context, limit int) (int, error) {
+	moved := 0
+	err := withTransaction(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+		var queued []*models.AuditOutboxEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("created_at, id").
+			Limit(limit).
+			Find(&queued).Error; err != nil {
+			return fmt.Errorf("failed to read audit outbox: %w", err)
+		}
+		if len(queued) == 0 {
+			return nil
+		}
+
+		entries := make([]*models.AuditLog, len(queued))
+		ids := make([]uuid.UUID, len(queued))
+		for i, q := range queued {
+			entries[i] = &q.Entry
+			ids[i] = q.ID
+		}
+		if err := database.WithoutAuditChanges(tx).CreateInBatches(entries, auditInsertBatch).Error; err != nil {
+			return fmt.Errorf("failed to write audit logs: %w", err)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.AuditOutboxEntry{}).Error; err != nil {
+			return fmt.Errorf("failed to clear audit outbox: %w", err)
+		}
+		moved = len(queued)
+		return nil
+	})
+	return moved, err
+}
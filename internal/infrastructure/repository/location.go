@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LocationRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+func (r *LocationRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
+
+func (r *LocationRepository) CreateOfficeNetwork(ctx context.Context, network *models.OfficeNetwork) error {
+	return conn(ctx, r.db).Create(network).Error
+}
+
+func (r *LocationRepository) FindOfficeNetworks(ctx context.Context) ([]*models.OfficeNetwork, error) {
+	var networks []*models.OfficeNetwork
+	if err := conn(ctx, r.db).Order("name").Find(&networks).Error; err != nil {
+		return nil, fmt.Errorf("failed to find office networks: %w", err)
+	}
+	return networks, nil
+}
+
+func (r *LocationRepository) DeleteOfficeNetwork(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Where("id = ?", id).Delete(&models.OfficeNetwork{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("office network not found")
+	}
+	return nil
+}
+
+func (r *LocationRepository) CreateGeofence(ctx context.Context, geofence *models.Geofence) error {
+	return conn(ctx, r.db).Create(geofence).Error
+}
+
+func (r *LocationRepository) FindGeofences(ctx context.Context) ([]*models.Geofence, error) {
+	var geofences []*models.Geofence
+	if err := conn(ctx, r.db).Order("name").Find(&geofences).Error; err != nil {
+		return nil, fmt.Errorf("failed to find geofences: %w", err)
+	}
+	return geofences, nil
+}
+
+func (r *LocationRepository) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Where("id = ?", id).Delete(&models.Geofence{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("geofence not found")
+	}
+	return nil
+}
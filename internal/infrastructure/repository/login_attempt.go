@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository stores login counters in Postgres so every instance
+// sees the same counts.
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) FindLoginAttempt(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := conn(ctx, r.db).Where("attempt_key = ? AND last_failure_at >= ?", key, time.Now().Add(-window)).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find login attempts: %w", err)
+	}
+	return &attempt, nil
+}
+
+// RecordLoginFailure increments the counter in a single statement so
+// concurrent failures on several instances are all counted.
+func (r *LoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	now := time.Now()
+	var attempt models.LoginAttempt
+	err := conn(ctx, r.db).Raw(`
+		INSERT INTO login_attempts (attempt_key, failures, last_failure_at) VALUES (?, 1, ?)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING attempt_key, failures, last_failure_at`,
+		key, now, now.Add(-window)).Scan(&attempt).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
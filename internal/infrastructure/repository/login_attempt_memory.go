@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"payslip/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// MemoryLoginAttemptRepository keeps login counters in process memory. It
+// suits a single instance; counters are lost on restart.
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*models.LoginAttempt
+}
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{attempts: map[string]*models.LoginAttempt{}}
+}
+
+func (r *MemoryLoginAttemptRepository) FindLoginAttempt(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(time.Now().Add(-window)) {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.prune(now.Add(-window))
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &models.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *MemoryLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// prune drops counters whose last failure is before cutoff so the map does
+// not grow without bound.
+func (r *MemoryLoginAttemptRepository) prune(cutoff time.Time) {
+	for key, attempt := range r.attempts {
+		if attempt.LastFailureAt.Before(cutoff) {
+			delete(r.attempts, key)
+		}
+	}
+}
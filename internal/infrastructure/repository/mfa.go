@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MFARepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) FindCredential(ctx context.Context, userID uuid.UUID) (*models.MFACredential, error) {
+	var credential models.MFACredential
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&credential).Error; err != nil {
+		return nil, fmt.Errorf("MFA credential not found: %w", err)
+	}
+	return &credential, nil
+}
+
+func (r *MFARepository) SaveCredential(ctx context.Context, credential *models.MFACredential) error {
+	return conn(ctx, r.db).Save(credential).Error
+}
+
+func (r *MFARepository) DeleteCredential(ctx context.Context, userID uuid.UUID) error {
+	db := conn(ctx, r.db)
+	if err := db.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&models.MFACredential{}).Error
+}
+
+func (r *MFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.MFACredential{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.MFARecoveryCode) error {
+	return withTransaction(ctx, r.db, func(tx context.Context) error {
+		if err := conn(tx, r.db).Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return conn(tx, r.db).Create(codes).Error
+	})
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *MFARepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+func (r *OrganizationRepository) CreateDepartment(ctx context.Context, department *models.Department) error {
+	return conn(ctx, r.db).Create(department).Error
+}
+
+func (r *OrganizationRepository) FindDepartments(ctx context.Context) ([]*models.Department, error) {
+	var departments []*models.Department
+	if err := conn(ctx, r.db).Order("name").Find(&departments).Error; err != nil {
+		return nil, fmt.Errorf("failed to find departments: %w", err)
+	}
+	return departments, nil
+}
+
+func (r *OrganizationRepository) FindDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error) {
+	var department models.Department
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&department).Error; err != nil {
+		return nil, fmt.Errorf("department not found: %w", err)
+	}
+	return &department, nil
+}
+
+// DeleteDepartment refuses to delete a department that still has members.
+func (r *OrganizationRepository) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+	var members int64
+	if err := conn(ctx, r.db).Model(&models.User{}).Where("department_id = ?", id).Count(&members).Error; err != nil {
+		return err
+	}
+	if members > 0 {
+		return fmt.Errorf("department still has %d members", members)
+	}
+
+	result := conn(ctx, r.db).Where("id = ?", id).Delete(&models.Department{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("department not found")
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) CreateCostCenter(ctx context.Context, costCenter *models.CostCenter) error {
+	return conn(ctx, r.db).Create(costCenter).Error
+}
+
+func (r *OrganizationRepository) FindCostCenters(ctx context.Context) ([]*models.CostCenter, error) {
+	var costCenters []*models.CostCenter
+	if err := conn(ctx, r.db).Order("code").Find(&costCenters).Error; err != nil {
+		return nil, fmt.Errorf("failed to find cost centers: %w", err)
+	}
+	return costCenters, nil
+}
+
+func (r *OrganizationRepository) FindCostCenterByID(ctx context.Context, id uuid.UUID) (*models.CostCenter, error) {
+	var costCenter models.CostCenter
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&costCenter).Error; err != nil {
+		return nil, fmt.Errorf("cost center not found: %w", err)
+	}
+	return &costCenter, nil
+}
+
+// DeleteCostCenter refuses to delete a cost center that users are still
+// charged to.
+func (r *OrganizationRepository) DeleteCostCenter(ctx context.Context, id uuid.UUID) error {
+	var members int64
+	if err := conn(ctx, r.db).Model(&models.User{}).Where("cost_center_id = ?", id).Count(&members).Error; err != nil {
+		return err
+	}
+	if members > 0 {
+		return fmt.Errorf("cost center still has %d members", members)
+	}
+
+	result := conn(ctx, r.db).Where("id = ?", id).Delete(&models.CostCenter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("cost center not found")
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) FindUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *OrganizationRepository) UpdateUserOrganization(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Model(user).Select("department_id", "cost_center_id", "manager_id", "updated_by").Updates(map[string]interface{}{
+		"department_id":  user.DepartmentID,
+		"cost_center_id": user.CostCenterID,
+		"manager_id":     user.ManagerID,
+		"updated_by":     user.UpdatedBy,
+	}).Error
+}
+
+func (r *OrganizationRepository) FindDirectReports(ctx context.Context, managerID uuid.UUID) ([]*models.User, error) {
+	var users []*models.User
+	if err := conn(ctx, r.db).Where("manager_id = ?", managerID).Order("username").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to find direct reports: %w", err)
+	}
+	return users, nil
+}
+
+func (r *OrganizationRepository) FindAttendancesByUsersAndPeriod(ctx context.Context, userIDs []uuid.UUID, periodID uuid.UUID) ([]*models.Attendance, error) {
+	var attendances []*models.Attendance
+	if len(userIDs) == 0 {
+		return attendances, nil
+	}
+	if err := conn(ctx, r.db).Where("user_id IN ? AND period_id = ?", userIDs, periodID).Order("date").Find(&attendances).Error; err != nil {
+		return nil, fmt.Errorf("failed to find attendances: %w", err)
+	}
+	return attendances, nil
+}
+
+func (r *OrganizationRepository) FindOvertimesByUsersAndPeriod(ctx context.Context, userIDs []uuid.UUID, periodID uuid.UUID) ([]*models.Overtime, error) {
+	var overtimes []*models.Overtime
+	if len(userIDs) == 0 {
+		return overtimes, nil
+	}
+	if err := conn(ctx, r.db).Where("user_id IN ? AND period_id = ?", userIDs, periodID).Order("date").Find(&overtimes).Error; err != nil {
+		return nil, fmt.Errorf("failed to find overtimes: %w", err)
+	}
+	return overtimes, nil
+}
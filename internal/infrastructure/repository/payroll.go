@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"payslip/internal/domain/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -17,13 +18,17 @@ func NewPayrollRepository(db *gorm.DB) *PayrollRepository {
 	return &PayrollRepository{db: db}
 }
 
+func (r *PayrollRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
+
 func (r *PayrollRepository) CreatePayroll(ctx context.Context, payroll *models.Payroll) error {
-	return r.db.WithContext(ctx).Create(payroll).Error
+	return conn(ctx, r.db).Create(payroll).Error
 }
 
 func (r *PayrollRepository) FindPayrollByPeriodAndUser(ctx context.Context, periodID, userID uuid.UUID) (*models.Payroll, error) {
 	var payroll models.Payroll
-	if err := r.db.WithContext(ctx).Where("period_id = ? AND user_id = ?", periodID, userID).First(&payroll).Error; err != nil {
+	if err := conn(ctx, r.db).Where("period_id = ? AND user_id = ?", periodID, userID).First(&payroll).Error; err != nil {
 		return nil, fmt.Errorf("payroll not found: %w", err)
 	}
 	return &payroll, nil
@@ -31,7 +36,7 @@ func (r *PayrollRepository) FindPayrollByPeriodAndUser(ctx context.Context, peri
 
 func (r *PayrollRepository) FindPayrollsByPeriod(ctx context.Context, periodID uuid.UUID) ([]*models.Payroll, error) {
 	var payrolls []*models.Payroll
-	if err := r.db.WithContext(ctx).Where("period_id = ?", periodID).Find(&payrolls).Error; err != nil {
+	if err := conn(ctx, r.db).Where("period_id = ?", periodID).Find(&payrolls).Error; err != nil {
 		return nil, fmt.Errorf("failed to find payrolls: %w", err)
 	}
 	return payrolls, nil
@@ -39,7 +44,7 @@ func (r *PayrollRepository) FindPayrollsByPeriod(ctx context.Context, periodID u
 
 func (r *PayrollRepository) FindAttendancesByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Attendance, error) {
 	var attendances []*models.Attendance
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&attendances).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&attendances).Error; err != nil {
 		return nil, fmt.Errorf("failed to find attendances: %w", err)
 	}
 	return attendances, nil
@@ -47,7 +52,7 @@ func (r *PayrollRepository) FindAttendancesByUserAndPeriod(ctx context.Context,
 
 func (r *PayrollRepository) FindOvertimesByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Overtime, error) {
 	var overtimes []*models.Overtime
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&overtimes).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&overtimes).Error; err != nil {
 		return nil, fmt.Errorf("failed to find overtimes: %w", err)
 	}
 	return overtimes, nil
@@ -55,15 +60,21 @@ func (r *PayrollRepository) FindOvertimesByUserAndPeriod(ctx context.Context, us
 
 func (r *PayrollRepository) FindReimbursementsByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]*models.Reimbursement, error) {
 	var reimbursements []*models.Reimbursement
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&reimbursements).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND period_id = ?", userID, periodID).Find(&reimbursements).Error; err != nil {
 		return nil, fmt.Errorf("failed to find reimbursements: %w", err)
 	}
 	return reimbursements, nil
 }
 
-func (r *PayrollRepository) FindEmployees(ctx context.Context) ([]*models.User, error) {
+// FindEmployees returns the employees active on at least one day between
+// start and end: hired by end and not deactivated or terminated before start.
+func (r *PayrollRepository) FindEmployees(ctx context.Context, start, end time.Time) ([]*models.User, error) {
 	var users []*models.User
-	if err := r.db.WithContext(ctx).Where("role = ?", "employee").Find(&users).Error; err != nil {
+	if err := conn(ctx, r.db).
+		Where("role = ?", "employee").
+		Where("hire_date IS NULL OR hire_date <= ?", end).
+		Where("status_effective_date IS NULL OR status_effective_date > ?", start).
+		Find(&users).Error; err != nil {
 		return nil, fmt.Errorf("failed to find employees: %w", err)
 	}
 	return users, nil
@@ -71,15 +82,23 @@ func (r *PayrollRepository) FindEmployees(ctx context.Context) ([]*models.User,
 
 func (r *PayrollRepository) CountAttendance(ctx context.Context, userID, periodID uuid.UUID) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&models.Attendance{}).Where("user_id = ? AND period_id = ?", userID, periodID).Count(&count).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&models.Attendance{}).Where("user_id = ? AND period_id = ? AND (review_status IS NULL OR review_status <> ?)", userID, periodID, "rejected").Count(&count).Error; err != nil {
 		return 0, fmt.Errorf("failed to count attendance: %w", err)
 	}
 	return count, nil
 }
 
+func (r *PayrollRepository) CountPendingReviews(ctx context.Context, periodID uuid.UUID) (int64, error) {
+	var count int64
+	if err := conn(ctx, r.db).Model(&models.Attendance{}).Where("period_id = ? AND review_status = ?", periodID, "pending").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending reviews: %w", err)
+	}
+	return count, nil
+}
+
 func (r *PayrollRepository) SumOvertimeHours(ctx context.Context, userID, periodID uuid.UUID) (float64, error) {
 	var totalHours float64
-	if err := r.db.WithContext(ctx).Model(&models.Overtime{}).Where("user_id = ? AND period_id = ?", userID, periodID).Select("SUM(hours)").Scan(&totalHours).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&models.Overtime{}).Where("user_id = ? AND period_id = ?", userID, periodID).Select("SUM(hours)").Scan(&totalHours).Error; err != nil {
 		return 0, fmt.Errorf("failed to sum overtime hours: %w", err)
 	}
 	return totalHours, nil
@@ -87,7 +106,7 @@ func (r *PayrollRepository) SumOvertimeHours(ctx context.Context, userID, period
 
 func (r *PayrollRepository) SumReimbursementAmount(ctx context.Context, userID, periodID uuid.UUID) (float64, error) {
 	var totalAmount float64
-	if err := r.db.WithContext(ctx).Model(&models.Reimbursement{}).Where("user_id = ? AND period_id = ?", userID, periodID).Select("SUM(amount)").Scan(&totalAmount).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&models.Reimbursement{}).Where("user_id = ? AND period_id = ?", userID, periodID).Select("SUM(amount)").Scan(&totalAmount).Error; err != nil {
 		return 0, fmt.Errorf("failed to sum reimbursement amount: %w", err)
 	}
 	return totalAmount, nil
@@ -95,8 +114,17 @@ func (r *PayrollRepository) SumReimbursementAmount(ctx context.Context, userID,
 
 func (r *PayrollRepository) FindUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := r.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := conn(ctx, r.db).Where("id = ?", userID).First(&user).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 	return &user, nil
 }
+
+// FindSalaryChanges returns the user's salary history, oldest first.
+func (r *PayrollRepository) FindSalaryChanges(ctx context.Context, userID uuid.UUID) ([]*models.SalaryChange, error) {
+	var changes []*models.SalaryChange
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("effective_date, created_at").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to find salary changes: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/database"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) FindRoles(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	if err := conn(ctx, r.db).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to find roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) FindRole(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := conn(ctx, r.db).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	return conn(ctx, r.db).Omit("Permissions").Create(role).Error
+}
+
+func (r *RoleRepository) UpdateRole(ctx context.Context, role *models.Role) error {
+	return conn(ctx, r.db).Omit("Permissions").Save(role).Error
+}
+
+func (r *RoleRepository) DeleteRole(ctx context.Context, name string) error {
+	if err := conn(ctx, r.db).Where("role_name = ?", name).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	return conn(ctx, r.db).Where("name = ?", name).Delete(&models.Role{}).Error
+}
+
+// SetPermissions replaces the permissions granted to the role.
+func (r *RoleRepository) SetPermissions(ctx context.Context, roleName string, permissions []string) error {
+	if err := conn(ctx, r.db).Where("role_name = ?", roleName).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	rows := make([]models.RolePermission, len(permissions))
+	for i, p := range permissions {
+		rows[i] = models.RolePermission{RoleName: roleName, Permission: p}
+	}
+	return conn(ctx, r.db).Create(&rows).Error
+}
+
+func (r *RoleRepository) FindPermissionsByRole(ctx context.Context, roleName string) ([]string, error) {
+	var permissions []string
+	if err := conn(ctx, r.db).Model(&models.RolePermission{}).Where("role_name = ?", roleName).Order("permission").Pluck("permission", &permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// SeedDefaultRoles creates the default roles of the tenant in ctx.
+func (r *RoleRepository) SeedDefaultRoles(ctx context.Context) error {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no tenant to seed roles for")
+	}
+	if err := database.SeedRoles(conn(ctx, r.db), id); err != nil {
+		return fmt.Errorf("failed to seed roles: %w", err)
+	}
+	return nil
+}
+
+func (r *RoleRepository) CountUsersWithRole(ctx context.Context, roleName string) (int64, error) {
+	var count int64
+	if err := conn(ctx, r.db).Model(&models.User{}).Where("role = ?", roleName).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+func (r *RoleRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
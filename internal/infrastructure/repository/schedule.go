@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// periodGenerationLockID is the Postgres advisory lock key that serialises
+// period generation, creation and updates across server instances and the
+// CLI, so overlap checks see every period committed before them.
+const periodGenerationLockID = 7310029
+
+type ScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+func (r *ScheduleRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
+
+func (r *ScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.PaySchedule) error {
+	return conn(ctx, r.db).Create(schedule).Error
+}
+
+func (r *ScheduleRepository) FindSchedules(ctx context.Context, activeOnly bool) ([]*models.PaySchedule, error) {
+	var schedules []*models.PaySchedule
+	query := conn(ctx, r.db).Order("created_at")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to find pay schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (r *ScheduleRepository) FindScheduleByID(ctx context.Context, id uuid.UUID) (*models.PaySchedule, error) {
+	var schedule models.PaySchedule
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("pay schedule not found: %w", err)
+	}
+	return &schedule, nil
+}
+
+func (r *ScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.PaySchedule) error {
+	return conn(ctx, r.db).Save(schedule).Error
+}
+
+func (r *ScheduleRepository) WithGenerationLock(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, func(tx context.Context) error {
+		if err := conn(tx, r.db).Exec("SELECT pg_advisory_xact_lock(?)", periodGenerationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire period generation lock: %w", err)
+		}
+		return fn(tx)
+	})
+}
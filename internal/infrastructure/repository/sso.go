@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SSORepository struct {
+	db *gorm.DB
+}
+
+func NewSSORepository(db *gorm.DB) *SSORepository {
+	return &SSORepository{db: db}
+}
+
+func (r *SSORepository) CreateOIDCLoginState(ctx context.Context, state *models.OIDCLoginState) error {
+	return conn(ctx, r.db).Create(state).Error
+}
+
+func (r *SSORepository) TakeOIDCLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	result := conn(ctx, r.db).Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("login state not found")
+	}
+	return &state, nil
+}
+
+func (r *SSORepository) FindUserByExternalSubject(ctx context.Context, issuer, subject string) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).Where("external_issuer = ? AND external_subject = ?", issuer, subject).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *SSORepository) UpdateExternalIdentity(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Model(user).Select("external_issuer", "external_subject", "updated_by").Updates(map[string]interface{}{
+		"external_issuer":  user.ExternalIssuer,
+		"external_subject": user.ExternalSubject,
+		"updated_by":       user.UpdatedBy,
+	}).Error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+func (r *TenantRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
+
+func (r *TenantRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	return conn(ctx, r.db).Create(tenant).Error
+}
+
+func (r *TenantRepository) FindTenants(ctx context.Context) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	if err := conn(ctx, r.db).Order("name").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tenants: %w", err)
+	}
+	return tenants, nil
+}
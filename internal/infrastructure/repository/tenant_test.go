@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"payslip/internal/domain/models"
+	"payslip/internal/domain/tenant"
+	"payslip/internal/infrastructure/database/dbtest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const periodTenantColumn = `"attendance_periods"."tenant_id" = `
+
+var (
+	tenantA = uuid.MustParse("0a000000-0000-0000-0000-00000000000a")
+	tenantB = uuid.MustParse("0b000000-0000-0000-0000-00000000000b")
+)
+
+func newPeriod(tenantID uuid.UUID) *models.AttendancePeriod {
+	return &models.AttendancePeriod{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		StartDate: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTenantScopeFiltersReads(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	repo.FindPeriodByID(ctx, uuid.New())
+	repo.FindPeriods(ctx, nil, nil, 20, 0)
+	repo.FindOverlappingPeriods(ctx, time.Now(), time.Now(), uuid.Nil)
+
+	statements := recorder.Statements(`FROM "attendance_periods"`)
+	if len(statements) != 4 {
+		t.Fatalf("got %d period queries, want 4", len(statements))
+	}
+	for _, s := range statements {
+		if !containsTenantFilter(s, tenantA) {
+			t.Errorf("query is not scoped to tenant A: %s %v", s.SQL, s.Args)
+		}
+		if s.HasArg(tenantB.String()) {
+			t.Errorf("query names tenant B: %s %v", s.SQL, s.Args)
+		}
+	}
+}
+
+func TestTenantScopeStampsNewRows(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	if err := repo.CreatePeriod(ctx, newPeriod(uuid.Nil)); err != nil {
+		t.Fatalf("CreatePeriod: %v", err)
+	}
+
+	inserts := recorder.Statements(`INSERT INTO "attendance_periods"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d inserts, want 1", len(inserts))
+	}
+	if got, _ := inserts[0].InsertValue("tenant_id"); got != tenantA.String() {
+		t.Errorf("tenant_id = %v, want tenant A", got)
+	}
+}
+
+func TestTenantScopeRejectsWritesToAnotherTenant(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	if err := repo.CreatePeriod(ctx, newPeriod(tenantB)); err == nil {
+		t.Error("CreatePeriod for tenant B succeeded in tenant A")
+	}
+	if err := repo.UpdatePeriod(ctx, newPeriod(tenantB)); err == nil {
+		t.Error("UpdatePeriod moved a period to tenant B from tenant A")
+	}
+	if statements := recorder.Statements(`"attendance_periods"`); len(statements) != 0 {
+		t.Errorf("rejected writes sent %d statements: %v", len(statements), statements)
+	}
+}
+
+func TestTenantScopeFiltersUpdatesAndDeletes(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	if err := repo.UpdatePeriod(ctx, newPeriod(tenantA)); err != nil {
+		t.Fatalf("UpdatePeriod: %v", err)
+	}
+	if err := repo.DeletePeriod(ctx, uuid.New()); err != nil {
+		t.Fatalf("DeletePeriod: %v", err)
+	}
+
+	for _, match := range []string{`UPDATE "attendance_periods"`, `DELETE FROM "attendance_periods"`} {
+		statements := recorder.Statements(match)
+		if len(statements) != 1 {
+			t.Fatalf("got %d statements matching %q, want 1", len(statements), match)
+		}
+		if !containsTenantFilter(statements[0], tenantA) {
+			t.Errorf("statement is not scoped to tenant A: %s %v", statements[0].SQL, statements[0].Args)
+		}
+	}
+}
+
+// The period generator begins its transaction in a system context and scopes
+// each schedule's work to the schedule's tenant inside it.
+func TestTenantScopeAppliesToTenantAddedInsideTransaction(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+
+	err := repo.WithTransaction(tenant.System(context.Background()), func(tx context.Context) error {
+		for _, id := range []uuid.UUID{tenantA, tenantB} {
+			tx := tenant.WithID(tx, id)
+			if _, err := repo.FindOverlappingPeriods(tx, time.Now(), time.Now(), uuid.Nil); err != nil {
+				return err
+			}
+			if err := repo.CreatePeriod(tx, newPeriod(uuid.Nil)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction: %v", err)
+	}
+
+	queries := recorder.Statements(`FROM "attendance_periods"`)
+	inserts := recorder.Statements(`INSERT INTO "attendance_periods"`)
+	if len(queries) != 2 || len(inserts) != 2 {
+		t.Fatalf("got %d queries and %d inserts, want 2 of each", len(queries), len(inserts))
+	}
+	for i, id := range []uuid.UUID{tenantA, tenantB} {
+		if !containsTenantFilter(queries[i], id) {
+			t.Errorf("query %d is not scoped to its tenant: %s %v", i, queries[i].SQL, queries[i].Args)
+		}
+		if got, _ := inserts[i].InsertValue("tenant_id"); got != id.String() {
+			t.Errorf("insert %d tenant_id = %v, want %s", i, got, id)
+		}
+	}
+}
+
+func TestTenantScopeRejectsStatementsWithoutTenant(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.FindPeriodByID(ctx, uuid.New()); !errors.Is(err, tenant.ErrNoTenant) {
+		t.Errorf("FindPeriodByID without a tenant: err = %v, want ErrNoTenant", err)
+	}
+	if err := repo.CreatePeriod(ctx, newPeriod(uuid.Nil)); !errors.Is(err, tenant.ErrNoTenant) {
+		t.Errorf("CreatePeriod without a tenant: err = %v, want ErrNoTenant", err)
+	}
+	if err := repo.DeletePeriod(ctx, uuid.New()); !errors.Is(err, tenant.ErrNoTenant) {
+		t.Errorf("DeletePeriod without a tenant: err = %v, want ErrNoTenant", err)
+	}
+	if statements := recorder.Statements(`"attendance_periods"`); len(statements) != 0 {
+		t.Errorf("ran statements without a tenant: %v", statements)
+	}
+}
+
+func TestTenantScopeSkipsSystemContexts(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewAttendanceRepository(db)
+	ctx := tenant.System(context.Background())
+
+	if _, err := repo.FindOverlappingPeriods(ctx, time.Now(), time.Now(), uuid.Nil); err != nil {
+		t.Fatalf("FindOverlappingPeriods: %v", err)
+	}
+	if err := repo.CreatePeriod(ctx, newPeriod(uuid.Nil)); err != nil {
+		t.Fatalf("CreatePeriod: %v", err)
+	}
+	if err := repo.CreatePeriod(ctx, newPeriod(tenantB)); err != nil {
+		t.Fatalf("CreatePeriod in a named tenant: %v", err)
+	}
+
+	queries := recorder.Statements(`FROM "attendance_periods"`)
+	if len(queries) != 1 || strings.Contains(queries[0].SQL, periodTenantColumn) {
+		t.Errorf("system query = %v, want one without a tenant filter", queries)
+	}
+	inserts := recorder.Statements(`INSERT INTO "attendance_periods"`)
+	for i, want := range []uuid.UUID{models.DefaultTenantID, tenantB} {
+		if got, _ := inserts[i].InsertValue("tenant_id"); got != want.String() {
+			t.Errorf("insert %d tenant_id = %v, want %s", i, got, want)
+		}
+	}
+}
+
+func containsTenantFilter(s dbtest.Statement, id uuid.UUID) bool {
+	return strings.Contains(s.SQL, periodTenantColumn) && s.HasArg(id.String())
+}
+
+func TestRolesAreScopedToTenant(t *testing.T) {
+	db, recorder := dbtest.Open(t)
+	repo := NewRoleRepository(db)
+	ctx := tenant.WithID(context.Background(), tenantA)
+
+	repo.FindPermissionsByRole(ctx, "admin")
+	if err := repo.SetPermissions(ctx, "admin", []string{"role:manage"}); err != nil {
+		t.Fatalf("SetPermissions: %v", err)
+	}
+
+	for _, match := range []string{`SELECT "permission" FROM "role_permissions"`, `DELETE FROM "role_permissions"`} {
+		statements := recorder.Statements(match)
+		if len(statements) != 1 {
+			t.Fatalf("got %d statements matching %q, want 1", len(statements), match)
+		}
+		if !strings.Contains(statements[0].SQL, `"role_permissions"."tenant_id" = `) || !statements[0].HasArg(tenantA.String()) {
+			t.Errorf("statement is not scoped to tenant A: %s %v", statements[0].SQL, statements[0].Args)
+		}
+	}
+	inserts := recorder.Statements(`INSERT INTO "role_permissions"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d inserts, want 1", len(inserts))
+	}
+	if got, _ := inserts[0].InsertValue("tenant_id"); got != tenantA.String() {
+		t.Errorf("permission granted in tenant %v, want tenant A", got)
+	}
+}
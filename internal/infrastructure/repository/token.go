@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"payslip/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+func (r *TokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := conn(ctx, r.db).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("refresh token not found: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken marks the token revoked and reports whether this call
+// did so, which lets concurrent refreshes of the same token detect reuse.
+func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *TokenRepository) RevokeUserSessions(ctx context.Context, revocation *models.SessionRevocation) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "updated_at", "updated_by"}),
+	}).Create(revocation).Error
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var count int64
+	if err := conn(ctx, r.db).Model(&models.RevokedToken{}).Where("jti = ? AND user_id = ?", jti, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := conn(ctx, r.db).Model(&models.SessionRevocation{}).
+		Where("user_id = ? AND revoked_before > ?", userID, issuedAt).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check session revocations: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *TokenRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
+	return withTransaction(ctx, r.db, fn)
+}
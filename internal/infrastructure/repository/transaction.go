@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// withTransaction runs fn inside a database transaction. Repositories pick the
+// transaction up from the context passed to fn, so writes made through any
+// repository sharing the same database commit or roll back together.
+func withTransaction(ctx context.Context, db *gorm.DB, fn func(tx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction stored in ctx, or db, bound to ctx. The
+// transaction is rebound because its statement context was fixed at begin,
+// and callbacks such as the tenant scope read values added to ctx since.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"payslip/internal/domain/models"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -19,18 +21,77 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := conn(ctx, r.db).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 	return &user, nil
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return conn(ctx, r.db).Create(user).Error
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Save(user).Error
+}
+
+func (r *UserRepository) CreateSalaryChange(ctx context.Context, change *models.SalaryChange) error {
+	return conn(ctx, r.db).Create(change).Error
+}
+
+// FindSalaryChanges returns the user's salary history, oldest first.
+func (r *UserRepository) FindSalaryChanges(ctx context.Context, userID uuid.UUID) ([]*models.SalaryChange, error) {
+	var changes []*models.SalaryChange
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("effective_date, created_at").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to find salary changes: %w", err)
+	}
+	return changes, nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hash string, updatedBy uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":   hash,
+		"updated_by": updatedBy,
+	}).Error
+}
+
+func (r *UserRepository) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+func (r *UserRepository) FindPasswordResetTokenByHash(ctx context.Context, hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := conn(ctx, r.db).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("password reset token not found: %w", err)
+	}
+	return &token, nil
+}
+
+// UsePasswordResetToken marks the token used and reports whether this call
+// did so, so a token cannot be redeemed twice by concurrent requests.
+func (r *UserRepository) UsePasswordResetToken(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// ExpireUserPasswordResetTokens invalidates every unused reset token of the
+// user.
+func (r *UserRepository) ExpireUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Update("expires_at", time.Now()).Error
 }
 
 func (r *UserRepository) WithTransaction(ctx context.Context, fn func(tx context.Context) error) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return fn(context.WithValue(ctx, "tx", tx))
-	})
+	return withTransaction(ctx, r.db, fn)
 }